@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	agent "go-ex-vm-agent"
+	"go-ex-vm-agent/internal/config"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  %[1]s [--config PATH] [--profile NAME]     run the agent
+  %[1]s validate --config PATH [--profile NAME]
+                                             check the config and exit
+  %[1]s config print --config PATH [--profile NAME] [--format yaml|json|toml] [--sources]
+                                             print the effective config or where each value came from
+  %[1]s config schema                         print the JSON Schema of the config
+  %[1]s config migrate --config PATH          upgrade the config file to the current version, keeping a backup
+
+Files listed in the "include" key of the config and %[3]s/*.yaml next to it are merged into the config:
+includes override the config, drop-ins override includes, conflicting values of two includes or of two
+drop-ins are reported with their files.
+Sections under "profiles.NAME" overlay the base sections when NAME is selected by --profile or %[4]s.
+Values may contain ${env:NAME}, ${file:PATH} and ${file:PATH|trim} references, resolved at load
+and reload time; "config print" shows the references, never the resolved values.
+Every config key can be overridden by an environment variable named after its path,
+e.g. agent.restart_options.delay by %[2]s. Lists and maps are set as JSON.
+Scalar environment overrides may contain references too, lists and maps set as JSON may not.
+
+Flags:
+`, os.Args[0], config.EnvName("agent.restart_options.delay"), "conf.d", agent.EnvPrefix+"_PROFILE")
+	pflag.PrintDefaults()
+}
+
+// runCommand выполняет подкоманду и возвращает код завершения процесса
+func runCommand(args []string) int {
+	switch {
+	case args[0] == "validate" && len(args) == 1:
+		return validateCommand(viper.GetString("config"), viper.GetString("profile"))
+	case args[0] == "config" && len(args) == 2 && args[1] == "print":
+		if viper.GetBool("sources") {
+			return configSourcesCommand(viper.GetString("config"), viper.GetString("profile"))
+		}
+		return configPrintCommand(viper.GetString("config"), viper.GetString("profile"), viper.GetString("format"))
+	case args[0] == "config" && len(args) == 2 && args[1] == "schema":
+		return configSchemaCommand()
+	case args[0] == "config" && len(args) == 2 && args[1] == "migrate":
+		return configMigrateCommand(viper.GetString("config"))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", strings.Join(args, " "))
+		usage()
+		return exitUsage
+	}
+}
+
+// validateCommand загружает конфиг и проверяет его валидаторами всех компонентов
+func validateCommand(path, profile string) int {
+	cfg, err := config.Load(path, profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if err := cfg.ValidateComponents(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+
+	details := ""
+	if profile := cfg.Profile(); profile != "" {
+		details += ", profile: " + profile
+	}
+	if files := cfg.Files(); len(files) > 1 {
+		details += ", merged files: " + strings.Join(files, ", ")
+	}
+	fmt.Printf("%s: config is valid%s\n", path, details)
+	return exitOK
+}
+
+// configPrintCommand выводит итоговый конфиг с примененными значениями по умолчанию
+func configPrintCommand(path, profile, format string) int {
+	ext := config.Ext(strings.ToLower(format))
+	if !ext.IsValid() {
+		fmt.Fprintf(os.Stderr, "unsupported format '%s', allowed values: %v\n", format, config.ValidConfigFormats)
+		return exitUsage
+	}
+
+	cfg, err := config.Load(path, profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	data, err := cfg.Encode(ext)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	_, _ = os.Stdout.Write(data)
+	return exitOK
+}
+
+// configSourcesCommand выводит источник значения каждого ключа конфига
+func configSourcesCommand(path, profile string) int {
+	cfg, err := config.Load(path, profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tSOURCE\tENV\tFILE")
+	for _, source := range cfg.Sources() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", source.Key, source.Source, source.Env, source.File)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// configMigrateCommand переписывает файл конфига в формате текущей версии, исходный файл сохраняется в backup
+func configMigrateCommand(path string) int {
+	result, err := config.MigrateFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if result.Backup == "" {
+		fmt.Printf("%s: config is already at version %d\n", path, result.To)
+		return exitOK
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(os.Stderr, "migrated:", warning)
+	}
+	fmt.Printf("%s: migrated from version %d to %d, backup: %s\n", path, result.From, result.To, result.Backup)
+	fmt.Println("comments and key order are not preserved, compare with the backup")
+	return exitOK
+}
+
+// configSchemaCommand выводит JSON Schema файла конфигурации
+func configSchemaCommand() int {
+	data, err := config.Schema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	_, _ = os.Stdout.Write(data)
+	return exitOK
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"valid.yaml": `version: 2
+logger:
+  level: info
+profiles:
+  prod:
+    logger:
+      level: warn
+`,
+		"invalid.yaml": `version: 2
+logger:
+  level: dbg
+agent:
+  restart_options:
+    max_restarts: 101
+`,
+		"unknown.yaml": `version: 2
+loger:
+  level: info
+`,
+		"merged.yaml": `version: 2
+include: [extra.yaml]
+`,
+		"extra.yaml": `logger:
+  level: debug
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		profile string
+		code    int
+		stdout  string
+		stderr  []string
+	}{
+		{name: "valid config", file: "valid.yaml", code: exitOK, stdout: "config is valid\n"},
+		{name: "valid profile", file: "valid.yaml", profile: "prod", code: exitOK, stdout: "config is valid, profile: prod\n"},
+		{
+			name:   "merged files",
+			file:   "merged.yaml",
+			code:   exitOK,
+			stdout: "config is valid, merged files: " + filepath.Join(dir, "merged.yaml") + ", " + filepath.Join(dir, "extra.yaml"),
+		},
+		{name: "unknown profile", file: "valid.yaml", profile: "stage", code: exitError, stderr: []string{"unknown profile 'stage'"}},
+		{
+			name:   "every problem is reported",
+			file:   "invalid.yaml",
+			code:   exitError,
+			stderr: []string{"logger.level: invalid log level 'dbg'", "agent.restart_options.max_restarts: must be at most 100"},
+		},
+		{name: "unknown key", file: "unknown.yaml", code: exitError, stderr: []string{"did you mean 'logger'?"}},
+		{name: "missing file", file: "missing.yaml", code: exitError, stderr: []string{"missing.yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code int
+			stdout, stderr := captureOutput(t, func() {
+				code = validateCommand(filepath.Join(dir, tt.file), tt.profile)
+			})
+			if code != tt.code {
+				t.Fatalf("validateCommand() = %d, want %d, stderr: %s", code, tt.code, stderr)
+			}
+			if tt.stdout != "" && !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("stdout = %q, want %q", stdout, tt.stdout)
+			}
+			for _, want := range tt.stderr {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("stderr = %q, want %q", stderr, want)
+				}
+			}
+		})
+	}
+}
+
+// captureOutput возвращает то, что fn вывела в stdout и stderr
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+	}()
+
+	var outWriter, errWriter *os.File
+	out := pipeOutput(t, &outWriter)
+	errOut := pipeOutput(t, &errWriter)
+	os.Stdout, os.Stderr = outWriter, errWriter
+	fn()
+	_ = outWriter.Close()
+	_ = errWriter.Close()
+	return <-out, <-errOut
+}
+
+// pipeOutput создает pipe, сохраняет его конец для записи в writer и возвращает все прочитанное из pipe
+func pipeOutput(t *testing.T, writer **os.File) <-chan string {
+	t.Helper()
+
+	reader, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	*writer = w
+	output := make(chan string, 1)
+	go func() {
+		defer reader.Close()
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+	return output
+}
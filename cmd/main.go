@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"os"
+	"sync/atomic"
 	"time"
 
 	agent "go-ex-vm-agent"
 	"go-ex-vm-agent/internal/config"
 	"go-ex-vm-agent/internal/logger"
+	"go-ex-vm-agent/internal/notifier"
 	"go-ex-vm-agent/internal/runner"
 	"go-ex-vm-agent/internal/worker"
 
@@ -16,6 +19,10 @@ import (
 
 func main() {
 	pflag.String("config", "", "Path to config file")
+	pflag.String("profile", "", "Config profile that overlays the base sections, also set by "+agent.EnvPrefix+"_PROFILE")
+	pflag.String("format", string(config.FormatYAML), "Output format of 'config print': yaml, json or toml")
+	pflag.Bool("sources", false, "Make 'config print' show where each value came from instead of the values")
+	pflag.Usage = usage
 	pflag.Parse()
 
 	viper.SetEnvPrefix(agent.EnvPrefix)
@@ -24,7 +31,17 @@ func main() {
 		panic(err)
 	}
 
-	cfg, err := config.Load(viper.GetString("config"))
+	// Подкоманды не запускают агента и завершают процесс с кодом результата
+	if args := pflag.Args(); len(args) > 0 {
+		os.Exit(runCommand(args))
+	}
+
+	run()
+}
+
+// run запускает агента
+func run() {
+	cfg, err := config.Load(viper.GetString("config"), viper.GetString("profile"))
 	if err != nil {
 		panic(err)
 	}
@@ -34,32 +51,52 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	for _, warning := range cfg.Warnings() {
+		agent.Logger.Warn().Msg(warning)
+	}
 
 	// Конфигурации для runner и worker
 	runnerConfig := cfg.Agent.ToRunnerConfig()
 	workerConfig := cfg.Agent.ToWorkerConfig()
 
+	// Runner создается после фабрики, но фабрика вызывается только при его запуске
+	var r *runner.Runner
+	storeConfigPaths(cfg)
+
 	// Фабрика задач
 	taskFactory := func() []worker.Task {
-		return []worker.Task{
-			worker.NewTickerTask("config-watcher", 5*time.Second, func(ctx context.Context) error {
-				agent.Logger.Debug().Msg("Config watcher tick")
-				// TODO: логика отслеживания конфига
-				return nil
-			}),
+		tasks := []worker.Task{
 			worker.NewTickerTask("health-check", 30*time.Second, func(ctx context.Context) error {
 				agent.Logger.Debug().Msg("Health check tick")
 				// TODO: логика health check
 				return nil
-			}),
+			}).WithOptions(worker.TaskOptions{Essential: true}),
+		}
+
+		// Изменение файлов конфигурации применяется через reload runner'а, см. loadReloadConfig.
+		// Набор файлов берется из последней загрузки конфигурации: новый include заменит задачу при reload
+		if paths := *watchedConfigPaths.Load(); len(paths) > 0 {
+			tasks = append(tasks, worker.NewFilesWatchTask("config-watcher", paths, 0, func(ctx context.Context) error {
+				return r.Reload()
+			}))
 		}
+		return tasks
 	}
 
 	// Создаем runner
-	r, err := runner.New(runnerConfig, workerConfig, agent.Logger, taskFactory)
+	r, err = runner.New(runnerConfig, workerConfig, agent.Logger, taskFactory)
 	if err != nil {
 		agent.Logger.Fatal().Err(err).Msg("Failed to create runner")
 	}
+	r.SetConfigLoader(loadReloadConfig)
+
+	// Отправка событий на webhook'и
+	n, err := notifier.New(cfg.Notifier.ToNotifierConfig(), r.Events())
+	if err != nil {
+		agent.Logger.Fatal().Err(err).Msg("Failed to create notifier")
+	}
+	n.Start(context.Background())
+	defer n.Stop()
 
 	// Запускаем runner
 	if err := r.Start(); err != nil {
@@ -73,3 +110,36 @@ func main() {
 
 	agent.Logger.Info().Msg("Application stopped gracefully")
 }
+
+// watchedConfigPaths файлы и каталог conf.d, за которыми следит config-watcher
+var watchedConfigPaths atomic.Pointer[[]string]
+
+// storeConfigPaths запоминает файлы, из которых загружена конфигурация, и каталог conf.d, чтобы заметить
+// новые drop-in'ы. Без файлов конфигурации следить не за чем
+func storeConfigPaths(cfg *config.Config) {
+	var paths []string
+	paths = append(paths, cfg.Files()...)
+	if info, err := os.Stat(cfg.DropInDir()); len(paths) > 0 && err == nil && info.IsDir() {
+		paths = append(paths, cfg.DropInDir())
+	}
+	watchedConfigPaths.Store(&paths)
+}
+
+// loadReloadConfig заново загружает конфиг при reload, в том числе ссылки ${env:..} и ${file:..}.
+// При ошибке reload не выполняется. Параметры вывода логов и notifier применяются только при перезапуске агента
+func loadReloadConfig() (runner.ReloadConfig, error) {
+	cfg, err := config.Load(viper.GetString("config"), viper.GetString("profile"))
+	if err != nil {
+		return runner.ReloadConfig{}, err
+	}
+	for _, warning := range cfg.Warnings() {
+		agent.Logger.Warn().Msg(warning)
+	}
+	storeConfigPaths(cfg)
+
+	return runner.ReloadConfig{
+		Runner:   cfg.Agent.ToRunnerConfig(),
+		Worker:   cfg.Agent.ToWorkerConfig(),
+		LogLevel: cfg.Logger.ToLoggerConfig().Level,
+	}, nil
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"path/filepath"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -13,25 +15,134 @@ type Config struct {
 
 	// Agent represents the configuration settings for the agent.
 	Agent agentConfig `mapstructure:"agent"`
+
+	// Notifier represents the configuration for delivering task and runner events to webhooks.
+	Notifier notifierConfig `mapstructure:"notifier"`
+
+	// sources holds the origin of every key, filled by Load.
+	sources []KeySource
+
+	// profile is the name of the applied profile, empty if none, filled by Load.
+	profile string
+
+	// references holds the values with ${env:..} and ${file:..} references by key path, filled by Load.
+	references map[string]reference
+
+	// warnings holds the deprecation warnings of the migrations applied by Load.
+	warnings []string
+
+	// files holds the paths of the merged config files in merge order, filled by Load.
+	files []string
 }
 
 // Load reads and parses a configuration file from the specified path and returns a Config object or an error.
-func Load(path string) (*Config, error) {
-	ext, err := getConfigFormatByExt(filepath.Ext(path))
+// profile names the section of the profiles key that overlays the base sections, empty for none, see applyProfile.
+// Unknown or misspelled keys are rejected with the closest valid key and, for YAML, the line number,
+// then all sections are validated at once, see Config.Validate.
+// The files listed in the include key of the main file and the conf.d/*.yaml drop-ins next to it are merged
+// into the main file, see mergeConfigFiles for the merge rules.
+// Files of older layout versions are migrated in memory with deprecation warnings, see Warnings and MigrateFile.
+// String values in the files and in environment overrides may reference environment variables and files,
+// see resolveReferences; the raw references instead of the resolved values are shown by Encode and in error messages.
+// Every key can be overridden by an environment variable, see EnvName; env values take precedence over the file.
+func Load(path, profile string) (*Config, error) {
+	files, err := readConfigFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	for _, file := range files {
+		version, warnings, err := migrateSettings(file)
+		if err != nil {
+			return nil, err
+		}
+		// An unversioned file in the current layout is not worth a warning
+		if len(warnings) > 0 {
+			cfg.warnings = append(cfg.warnings, warnings...)
+			cfg.warnings = append(cfg.warnings, fmt.Sprintf(
+				"%s: config version %d is deprecated, run 'config migrate' to upgrade it to version %d",
+				file.path, version, CurrentVersion))
+		}
+		if err = checkUnknownKeys(file.path, file.format, file.settings, cfg); err != nil {
+			return nil, err
+		}
+	}
+	settings, origins, err := mergeConfigFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile is applied before references are resolved, so secrets of other profiles are not required
+	if err = applyProfile(settings, profile); err != nil {
+		return nil, err
+	}
+	cfg.profile = profile
+
+	references, err := resolveReferences(settings)
 	if err != nil {
 		return nil, err
 	}
+	cfg.references = references
 
 	v := viper.New()
-	v.SetConfigFile(path)
-	v.SetConfigType(string(ext))
-	if err = v.ReadInConfig(); err != nil {
-		return nil, initError("read file error: %s", err.Error())
+	registerDefaults(v)
+	if err = bindEnv(v); err != nil {
+		return nil, err
+	}
+	if err = resolveEnvReferences(v, references); err != nil {
+		return nil, err
+	}
+	if err = v.MergeConfigMap(settings); err != nil {
+		return nil, initError("merge error: %s", err.Error())
+	}
+	if err = v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+		dc.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+			jsonStringHook(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		)
+	}); err != nil {
+		return nil, initError("serialization error: %s", cfg.redact(err.Error()))
+	}
+	cfg.setDefaults()
+	cfg.sources = resolveSources(v, origins, profile)
+	for _, file := range files {
+		cfg.files = append(cfg.files, file.path)
 	}
 
-	var cfg Config
-	if err = v.Unmarshal(&cfg); err != nil {
-		return nil, initError("serialization error: %s", err.Error())
+	if err = cfg.Validate(); err != nil {
+		return nil, err
 	}
 	return &cfg, nil
 }
+
+// Warnings returns the deprecation warnings of the config layout migrations applied by Load.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// Profile returns the name of the profile applied by Load, empty if none.
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// Files returns the paths of the config files merged by Load, the main file first.
+func (c *Config) Files() []string {
+	return c.files
+}
+
+// DropInDir returns the conf.d directory next to the main config file, whose drop-ins are merged by Load.
+// The directory may not exist.
+func (c *Config) DropInDir() string {
+	if len(c.files) == 0 {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(c.files[0]), dropInDir)
+}
+
+// Sources returns the origin of every config key sorted by key.
+func (c *Config) Sources() []KeySource {
+	return c.sources
+}
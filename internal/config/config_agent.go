@@ -16,37 +16,64 @@ type agentConfig struct {
 	TaskOptions agentTaskOptions `mapstructure:"task_options"`
 
 	// WorkersTimeout specifies the duration to wait for workers to complete during a graceful shutdown process.
-	WorkersTimeout time.Duration `mapstructure:"graceful_shutdown_workers_timeout"`
+	WorkersTimeout time.Duration `mapstructure:"graceful_shutdown_workers_timeout" validate:"omitempty,min=1s,max=2m"`
 
 	// RunnerTimeout specifies the duration allowed for the agent to shut down gracefully before being forcefully terminated.
-	RunnerTimeout time.Duration `mapstructure:"graceful_shutdown_agent_timeout"`
+	RunnerTimeout time.Duration `mapstructure:"graceful_shutdown_agent_timeout" validate:"omitempty,min=1s,max=5m"`
+
+	// StateDir specifies the directory where the agent persists its state between process restarts.
+	StateDir string `mapstructure:"state_dir"`
+
+	// PIDFile specifies the pidfile used as a lock that prevents several agents from running on the same host.
+	PIDFile string `mapstructure:"pid_file"`
+
+	// UpgradeTimeout specifies how long the new binary may take to report readiness during a zero-downtime upgrade.
+	UpgradeTimeout time.Duration `mapstructure:"upgrade_timeout" validate:"omitempty,min=1s,max=10m"`
 }
 
 // agentRestartOptions defines configuration settings related to restarting an agent.
 type agentRestartOptions struct {
 	// Delay specifies the time duration to wait before attempting an agent restart.
-	Delay time.Duration `mapstructure:"delay"`
+	Delay time.Duration `mapstructure:"delay" validate:"omitempty,min=1s,max=1m"`
 
 	// MaxRestarts specifies the maximum number of times the agent will attempt to restart.
-	MaxRestarts int `mapstructure:"max_restarts"`
+	MaxRestarts int `mapstructure:"max_restarts" validate:"min=0,max=100"`
 
 	// RestartExponent determines whether an exponential backoff delay strategy is applied between restart attempts.
 	RestartExponent bool `mapstructure:"restart_exponent"`
 
 	// RestartOnFailure determines whether the agent should automatically restart upon encountering a failure or crash.
 	RestartOnFailure bool `mapstructure:"restart_on_failure"`
+
+	// MaxDelay specifies the upper bound of the restart delay when exponential backoff is enabled.
+	MaxDelay time.Duration `mapstructure:"max_delay" validate:"omitempty,min=1s,max=1h"`
+
+	// StableWindow specifies the uptime after which the restart counter is reset.
+	StableWindow time.Duration `mapstructure:"stable_window" validate:"omitempty,min=1s,max=24h"`
+
+	// DegradedMode determines whether the agent keeps essential tasks running once restarts are exhausted.
+	DegradedMode bool `mapstructure:"degraded_mode"`
+
+	// DegradedRetryInterval specifies how often a full restart is attempted while the agent is degraded.
+	DegradedRetryInterval time.Duration `mapstructure:"degraded_retry_interval" validate:"omitempty,min=1s,max=24h"`
 }
 
 // agentTaskOptions defines configuration options for controlling task execution behavior in the agent.
 type agentTaskOptions struct {
 	// MaxTimeout specifies the maximum duration allowed for a task to execute before it is forcibly terminated.
-	MaxTimeout time.Duration `mapstructure:"max_task_timeout"`
+	MaxTimeout time.Duration `mapstructure:"max_task_timeout" validate:"omitempty,min=1s"`
 
 	// MaxCount specifies the maximum number of tasks that can be executed concurrently.
-	MaxCount int `mapstructure:"max_task_count"`
+	MaxCount int `mapstructure:"max_task_count" validate:"omitempty,min=1,max=1000"`
 
 	// StopOnFailure determines if task execution should stop when a failure is encountered.
 	StopOnFailure bool `mapstructure:"stop_on_failure"`
+
+	// HistorySize specifies how many of the latest executions are kept in the history of each task.
+	HistorySize int `mapstructure:"history_size" validate:"omitempty,min=1,max=1000"`
+
+	// HistoryFile specifies the file where task execution history is persisted across agent restarts.
+	HistoryFile string `mapstructure:"history_file"`
 }
 
 // ToRunnerConfig transforms an agentConfig instance into the runner.Config structure used by the runner package.
@@ -56,7 +83,15 @@ func (ac agentConfig) ToRunnerConfig() runner.Config {
 		ExponentialBackoff: ac.RestartOptions.RestartExponent,
 		MaxRestarts:        ac.RestartOptions.MaxRestarts,
 		RestartDelay:       ac.RestartOptions.Delay,
-		ShutdownTimeout:    ac.RunnerTimeout,
+		MaxRestartDelay:    ac.RestartOptions.MaxDelay,
+		StableWindow:       ac.RestartOptions.StableWindow,
+
+		EnableDegradedMode:    ac.RestartOptions.DegradedMode,
+		DegradedRetryInterval: ac.RestartOptions.DegradedRetryInterval,
+		ShutdownTimeout:       ac.RunnerTimeout,
+		StateDir:              ac.StateDir,
+		PIDFile:               ac.PIDFile,
+		UpgradeTimeout:        ac.UpgradeTimeout,
 	}
 }
 
@@ -64,8 +99,10 @@ func (ac agentConfig) ToRunnerConfig() runner.Config {
 func (ac agentConfig) ToWorkerConfig() worker.Config {
 	return worker.Config{
 		StopOnError:     ac.TaskOptions.StopOnFailure,
-		TaskStopTimeout: ac.TaskOptions.MaxTimeout,
+		TaskStopTimeout: ac.WorkersTimeout,
 		TaskTimeout:     ac.TaskOptions.MaxTimeout,
 		MaxTasks:        ac.TaskOptions.MaxCount,
+		HistorySize:     ac.TaskOptions.HistorySize,
+		HistoryFile:     ac.TaskOptions.HistoryFile,
 	}
 }
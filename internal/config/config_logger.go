@@ -5,13 +5,13 @@ import "go-ex-vm-agent/internal/logger"
 // loggerConfig represents the configuration settings for the logger.
 type loggerConfig struct {
 	// Level specifies the logging level for the logger configuration.
-	Level logger.LogLevel `mapstructure:"level"`
+	Level logger.LogLevel `mapstructure:"level" validate:"omitempty,log_level"`
 
 	// Format specifies the format of the logs (e.g., JSON, plain text).
-	Format logger.LogFormat `mapstructure:"format"`
+	Format logger.LogFormat `mapstructure:"format" validate:"omitempty,log_format"`
 
 	// Output specifies the destination where log messages should be written (e.g., file, stdout, stderr).
-	Output logger.LogOutput `mapstructure:"output"`
+	Output logger.LogOutput `mapstructure:"output" validate:"omitempty,log_output"`
 
 	// FileOptions defines file-specific configuration options for logger output, such as file path, size, age, and backups.
 	FileOptions loggerFileOptions `mapstructure:"options"`
@@ -20,13 +20,13 @@ type loggerConfig struct {
 // loggerFileOptions defines configuration for file-based logging.
 type loggerFileOptions struct {
 	// MaxBackups specifies the maximum number of backup log files to retain.
-	MaxBackups int `mapstructure:"max_backups"`
+	MaxBackups int `mapstructure:"max_backups" validate:"omitempty,min=0,max=100"`
 
 	// MaxAge specifies the maximum number of days logs will be retained before being automatically deleted.
-	MaxAge int `mapstructure:"max_age"`
+	MaxAge int `mapstructure:"max_age" validate:"omitempty,min=1,max=365"`
 
 	// MaxSize specifies the maximum size (in MB) of the log file before it is rotated.
-	MaxSize int `mapstructure:"max_size"`
+	MaxSize int `mapstructure:"max_size" validate:"omitempty,min=1,max=512"`
 
 	// Compress indicates whether old log files should be compressed using gzip.
 	Compress bool `mapstructure:"compress"`
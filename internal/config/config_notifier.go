@@ -0,0 +1,79 @@
+package config
+
+import (
+	"time"
+
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/notifier"
+)
+
+// notifierConfig represents the configuration settings for event notifications.
+type notifierConfig struct {
+	// Webhooks defines the HTTP endpoints that receive task and runner events.
+	Webhooks []notifierWebhook `mapstructure:"webhooks" validate:"dive"`
+
+	// QueueDir specifies the directory where undelivered events are kept while an endpoint is down.
+	QueueDir string `mapstructure:"queue_dir"`
+
+	// QueueSize specifies the maximum number of undelivered events kept per webhook.
+	QueueSize int `mapstructure:"queue_size" validate:"omitempty,min=1,max=100000"`
+}
+
+// notifierWebhook defines a single webhook endpoint.
+type notifierWebhook struct {
+	// Name specifies the unique webhook name used in logs and as the queue subdirectory.
+	Name string `mapstructure:"name" validate:"required,webhook_name"`
+
+	// URL specifies the endpoint that receives event payloads via HTTP POST.
+	URL string `mapstructure:"url" validate:"required,http_url"`
+
+	// Events specifies the event types sent to the webhook, all events are sent when empty.
+	Events []string `mapstructure:"events" validate:"dive,event_type"`
+
+	// Secret specifies the key used to sign request bodies with HMAC-SHA256.
+	Secret string `mapstructure:"secret"`
+
+	// Headers specifies additional HTTP headers sent with every request.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Timeout specifies the timeout of a single HTTP request.
+	Timeout time.Duration `mapstructure:"timeout" validate:"omitempty,min=1s,max=5m"`
+
+	// MaxRetries specifies how many times a failed delivery is retried, 0 means the default and -1 disables retries.
+	MaxRetries int `mapstructure:"max_retries" validate:"min=-1,max=20"`
+
+	// RetryDelay specifies the delay before the first retry, doubled on every next retry.
+	RetryDelay time.Duration `mapstructure:"retry_delay" validate:"omitempty,min=100ms,max=1m"`
+
+	// MaxRetryDelay specifies the upper bound of the delay between retries.
+	MaxRetryDelay time.Duration `mapstructure:"max_retry_delay" validate:"omitempty,min=1s,max=1h"`
+}
+
+// ToNotifierConfig transforms a notifierConfig instance into the notifier.Config structure used by the notifier package.
+func (nc notifierConfig) ToNotifierConfig() notifier.Config {
+	webhooks := make([]notifier.WebhookConfig, 0, len(nc.Webhooks))
+	for _, wh := range nc.Webhooks {
+		eventTypes := make([]events.EventType, 0, len(wh.Events))
+		for _, e := range wh.Events {
+			eventTypes = append(eventTypes, events.EventType(e))
+		}
+
+		webhooks = append(webhooks, notifier.WebhookConfig{
+			Name:          wh.Name,
+			URL:           wh.URL,
+			Events:        eventTypes,
+			Secret:        wh.Secret,
+			Headers:       wh.Headers,
+			Timeout:       wh.Timeout,
+			MaxRetries:    wh.MaxRetries,
+			RetryDelay:    wh.RetryDelay,
+			MaxRetryDelay: wh.MaxRetryDelay,
+		})
+	}
+
+	return notifier.Config{
+		Webhooks:  webhooks,
+		QueueDir:  nc.QueueDir,
+		QueueSize: nc.QueueSize,
+	}
+}
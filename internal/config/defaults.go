@@ -0,0 +1,110 @@
+package config
+
+import (
+	"time"
+
+	"go-ex-vm-agent/internal/logger"
+
+	"github.com/spf13/viper"
+)
+
+// defaultConfig returns a Config populated with the same defaults the logger, runner, worker and notifier
+// packages apply. Zero values that are left here keep the component default.
+func defaultConfig() Config {
+	return Config{
+		Logger: loggerConfig{
+			Level:  logger.LevelInfo,
+			Format: logger.FormatJSON,
+			Output: logger.OutputStdout,
+			FileOptions: loggerFileOptions{
+				MaxBackups: 3,
+				MaxAge:     28,
+				MaxSize:    100,
+			},
+		},
+		Agent: agentConfig{
+			RestartOptions: agentRestartOptions{
+				Delay:                 10 * time.Second,
+				MaxDelay:              10 * time.Minute,
+				StableWindow:          10 * time.Minute,
+				DegradedRetryInterval: 5 * time.Minute,
+			},
+			TaskOptions: agentTaskOptions{
+				MaxTimeout:  5 * time.Minute,
+				MaxCount:    100,
+				HistorySize: 20,
+			},
+			WorkersTimeout: 10 * time.Second,
+			RunnerTimeout:  60 * time.Second,
+			UpgradeTimeout: 30 * time.Second,
+		},
+		Notifier: notifierConfig{
+			QueueSize: 1000,
+		},
+	}
+}
+
+// defaultWebhook returns the defaults of a single notifier webhook.
+func defaultWebhook() notifierWebhook {
+	return notifierWebhook{
+		Timeout:       10 * time.Second,
+		MaxRetries:    3,
+		RetryDelay:    time.Second,
+		MaxRetryDelay: time.Minute,
+	}
+}
+
+// registerDefaults registers every non-zero value of defaultConfig as a viper default.
+func registerDefaults(v *viper.Viper) {
+	for key, value := range flattenKeys(toMap(defaultConfig()), "") {
+		v.SetDefault(key, value)
+	}
+}
+
+// setDefaults fills the fields viper defaults cannot reach, such as list items.
+func (c *Config) setDefaults() {
+	defaults := defaultWebhook()
+
+	for i := range c.Notifier.Webhooks {
+		webhook := &c.Notifier.Webhooks[i]
+		if webhook.Timeout == 0 {
+			webhook.Timeout = defaults.Timeout
+		}
+		if webhook.MaxRetries == 0 {
+			webhook.MaxRetries = defaults.MaxRetries
+		}
+		if webhook.RetryDelay == 0 {
+			webhook.RetryDelay = defaults.RetryDelay
+		}
+		if webhook.MaxRetryDelay == 0 {
+			webhook.MaxRetryDelay = defaults.MaxRetryDelay
+		}
+	}
+}
+
+// flattenKeys converts nested maps into dotted keys, skipping zero values.
+func flattenKeys(values map[string]any, prefix string) map[string]any {
+	flat := make(map[string]any)
+	for key, value := range values {
+		key = joinKey(prefix, key)
+		switch v := value.(type) {
+		case map[string]any:
+			for k, nested := range flattenKeys(v, key) {
+				flat[k] = nested
+			}
+		case string:
+			if v != "" && v != "0s" {
+				flat[key] = v
+			}
+		case int:
+			if v != 0 {
+				flat[key] = v
+			}
+		case bool:
+			if v {
+				flat[key] = v
+			}
+		}
+	}
+	return flat
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	agent "go-ex-vm-agent"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// Source describes where the effective value of a config key came from.
+type Source string
+
+const (
+	// SourceDefault means the value comes from defaultConfig.
+	SourceDefault Source = "default"
+
+	// SourceFile means the value is set in the config file.
+	SourceFile Source = "file"
+
+	// SourceProfile means the value is set by the profile selected in Load.
+	SourceProfile Source = "profile"
+
+	// SourceEnv means the value is overridden by an environment variable.
+	SourceEnv Source = "env"
+
+	// SourceUnset means the key is not set anywhere and the component applies its own default.
+	SourceUnset Source = "unset"
+)
+
+// KeySource reports the origin of a single config key.
+type KeySource struct {
+	// Key is the dotted key path, e.g. "agent.restart_options.delay".
+	Key string
+
+	// Source is where the effective value came from.
+	Source Source
+
+	// Env is the environment variable that overrides the key.
+	Env string
+
+	// File is the config file the value came from, files are separated by ", " for lists merged from several.
+	File string
+}
+
+// envKeyReplacer maps the dotted key path to the environment variable suffix.
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// EnvName returns the environment variable that overrides the key, e.g. "agent.restart_options.delay"
+// is overridden by VM_AGENT_AGENT_RESTART_OPTIONS_DELAY. Lists and maps are set as JSON.
+func EnvName(key string) string {
+	return agent.EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// bindEnv binds every config key to its environment variable. Keys are bound explicitly because
+// AutomaticEnv only sees keys that are already present in the file or the defaults.
+func bindEnv(v *viper.Viper) error {
+	v.SetEnvPrefix(agent.EnvPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		if err := v.BindEnv(key); err != nil {
+			return initError("failed to bind env for '%s': %v", key, err)
+		}
+	}
+	return nil
+}
+
+// resolveEnvReferences resolves ${env:..} and ${file:..} references in environment overrides the same way
+// as in config files and records them in references, replacing the reference of the overridden file value.
+// Lists and maps set as JSON may not contain references, since a resolved value would need JSON escaping.
+// Every unresolved or rejected reference is reported in a single *ValidationError.
+func resolveEnvReferences(v *viper.Viper, references map[string]reference) error {
+	result := &ValidationError{}
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		name := EnvName(key)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		delete(references, key)
+		if !referencePattern.MatchString(value) {
+			continue
+		}
+
+		raw := strings.TrimSpace(value)
+		if strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") {
+			result.add(key, "%s: references are not supported in lists and maps set as JSON", name)
+			continue
+		}
+		errs := &ValidationError{}
+		resolved := resolveValue(value, key, references, errs)
+		for _, field := range errs.Fields {
+			result.add(field.Path, "%s: %s", name, field.Message)
+		}
+		v.Set(key, resolved)
+	}
+
+	if len(result.Fields) == 0 {
+		return nil
+	}
+	return result
+}
+
+// resolveSources determines the origin of every config key. origins holds the files every key was set in,
+// profile is the applied profile.
+func resolveSources(v *viper.Viper, origins map[string][]string, profile string) []KeySource {
+	defaults := flattenKeys(toMap(defaultConfig()), "")
+
+	keys := configKeys(reflect.TypeOf(Config{}), "")
+	sources := make([]KeySource, 0, len(keys))
+	for _, key := range keys {
+		source := KeySource{Key: key, Source: SourceUnset, Env: EnvName(key)}
+		if value, ok := os.LookupEnv(source.Env); ok && value != "" {
+			source.Source = SourceEnv
+		} else if files := keyFiles(origins, profileKey(profile, key)); profile != "" && len(files) > 0 {
+			source.Source = SourceProfile
+			source.File = strings.Join(files, ", ")
+		} else if v.InConfig(key) {
+			source.Source = SourceFile
+			source.File = strings.Join(keyFiles(origins, key), ", ")
+		} else if _, ok := defaults[key]; ok {
+			source.Source = SourceDefault
+		}
+		sources = append(sources, source)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Key < sources[j].Key
+	})
+	return sources
+}
+
+// keyFiles returns the files that set key or, for map keys, any of its entries.
+func keyFiles(origins map[string][]string, key string) []string {
+	if files, ok := origins[key]; ok {
+		return files
+	}
+
+	var files []string
+	seen := make(map[string]struct{})
+	for path, paths := range origins {
+		if path != key && !strings.HasPrefix(path, key+".") {
+			continue
+		}
+		for _, file := range paths {
+			if _, ok := seen[file]; !ok {
+				seen[file] = struct{}{}
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// configKeys returns the dotted paths of all leaf keys of t. Lists and maps are leaves.
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		key := joinKey(prefix, name)
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			keys = append(keys, configKeys(field.Type, key)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// jsonStringHook decodes JSON strings from environment variables into lists and maps,
+// e.g. VM_AGENT_NOTIFIER_WEBHOOKS='[{"name":"ops","url":"https://..."}]'.
+func jsonStringHook() mapstructure.DecodeHookFuncKind {
+	return func(from, to reflect.Kind, data any) (any, error) {
+		if from != reflect.String || (to != reflect.Slice && to != reflect.Map) {
+			return data, nil
+		}
+		raw := strings.TrimSpace(data.(string))
+		if !strings.HasPrefix(raw, "[") && !strings.HasPrefix(raw, "{") {
+			return data, nil
+		}
+
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, parseError("invalid JSON value %q: %v", raw, err)
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "logger.level", want: "VM_AGENT_LOGGER_LEVEL"},
+		{key: "agent.restart_options.delay", want: "VM_AGENT_AGENT_RESTART_OPTIONS_DELAY"},
+		{key: "notifier.webhooks", want: "VM_AGENT_NOTIFIER_WEBHOOKS"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(tt.key); got != tt.want {
+			t.Errorf("EnvName(%q) = %s, want %s", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLoadAppliesEnvOverrides(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `version: 2
+logger:
+  level: info
+agent:
+  restart_options:
+    delay: 5s
+profiles:
+  prod:
+    logger:
+      level: warn
+`)
+	t.Setenv("TEST_DELAY", "7s")
+	t.Setenv(EnvName("agent.restart_options.delay"), "${env:TEST_DELAY}")
+	t.Setenv(EnvName("logger.level"), "debug")
+	t.Setenv(EnvName("notifier.webhooks"), `[{"name":"ops","url":"https://example.com/hook","events":["task.failed"]}]`)
+
+	cfg, err := Load(path, "prod")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Agent.RestartOptions.Delay != 7*time.Second {
+		t.Errorf("delay = %v, want 7s from the env reference", cfg.Agent.RestartOptions.Delay)
+	}
+	if cfg.Logger.Level != "debug" {
+		t.Errorf("level = %s, want debug from env over the profile", cfg.Logger.Level)
+	}
+	webhooks := cfg.Notifier.Webhooks
+	if len(webhooks) != 1 || webhooks[0].Name != "ops" || strings.Join(webhooks[0].Events, ",") != "task.failed" {
+		t.Errorf("webhooks = %+v, want ops from JSON", webhooks)
+	}
+
+	sources := keySources(cfg)
+	for _, key := range []string{"agent.restart_options.delay", "logger.level", "notifier.webhooks"} {
+		if got := sources[key]; got.Source != SourceEnv || got.Env != EnvName(key) {
+			t.Errorf("%s source = %+v, want env %s", key, got, EnvName(key))
+		}
+	}
+}
+
+func TestLoadRejectsReferencesInJSONEnv(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "version: 2\n")
+	t.Setenv("TEST_WEBHOOK_URL", "https://example.com/hook")
+	t.Setenv(EnvName("notifier.webhooks"), `[{"name":"ops","url":"${env:TEST_WEBHOOK_URL}"}]`)
+
+	_, err := Load(path, "")
+	want := "VM_AGENT_NOTIFIER_WEBHOOKS: references are not supported in lists and maps set as JSON"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("Load() error = %v, want %q", err, want)
+	}
+}
@@ -8,6 +8,9 @@ const (
 
 	// ErrInitializeConfig represents an error message format for failures during the initialization of a configuration.
 	ErrInitializeConfig = "failed to initialize config: %s"
+
+	// ErrConfigValidate represents an error message format for configuration values that fail validation.
+	ErrConfigValidate = "invalid config: %s"
 )
 
 func initError(format string, args ...any) error {
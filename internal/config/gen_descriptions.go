@@ -0,0 +1,100 @@
+//go:build ignore
+
+// gen_descriptions extracts doc comments of config struct fields into schema_descriptions.go,
+// so the JSON Schema can describe every key. Run via "go generate ./internal/config".
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const output = "schema_descriptions.go"
+
+func main() {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		name := info.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != output && name != "gen_descriptions.go"
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	descriptions := make(map[string]string)
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				st, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					return false
+				}
+				for _, field := range st.Fields.List {
+					// Only config keys are described, helper types have no mapstructure tags
+					if field.Tag == nil || !strings.Contains(field.Tag.Value, "mapstructure:") {
+						continue
+					}
+					doc := field.Doc
+					if doc == nil {
+						doc = field.Comment
+					}
+					if doc == nil {
+						continue
+					}
+					for _, name := range field.Names {
+						descriptions[spec.Name.Name+"."+name.Name] = describe(name.Name, doc.Text())
+					}
+				}
+				return false
+			})
+		}
+	}
+
+	keys := make([]string, 0, len(descriptions))
+	for key := range descriptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_descriptions.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package config\n\n")
+	buf.WriteString("// fieldDescriptions holds doc comments of config struct fields keyed by \"type.Field\".\n")
+	buf.WriteString("var fieldDescriptions = map[string]string{\n")
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", key, descriptions[key])
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// describe turns "Delay specifies the ..." into "Specifies the ...".
+func describe(field, doc string) string {
+	text := strings.Join(strings.Fields(doc), " ")
+	if rest, ok := strings.CutPrefix(text, field+" "); ok {
+		text = rest
+	}
+	r, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(r)) + text[size:]
+}
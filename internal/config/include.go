@@ -0,0 +1,342 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// includeKey is the top-level key of the main config file that lists additional files to merge.
+	includeKey = "include"
+
+	// dropInDir is the directory next to the main config file whose *.yaml files are merged automatically.
+	dropInDir = "conf.d"
+)
+
+// Merge layers of config files, see mergeConfigFiles.
+const (
+	layerMain = iota
+	layerInclude
+	layerDropIn
+)
+
+// configFile is a single file that takes part in the merged config.
+type configFile struct {
+	path     string
+	format   Ext
+	settings map[string]any
+	// layer is the merge layer of the file: the main file, an include or a drop-in.
+	layer int
+}
+
+// conflict describes a key that is set to different values by two files of the same layer.
+type conflict struct {
+	key           string
+	first, second *configFile
+	firstValue    any
+	secondValue   any
+	// item is the name of a list item defined twice, empty for scalar conflicts.
+	item string
+}
+
+// readConfigFiles reads the main config file, the files listed in its include key and the conf.d/*.yaml
+// drop-ins, in this order. Relative include paths and globs are resolved against the directory of the main
+// file, glob matches and drop-ins are sorted by name. A file reached twice is read once at its first position.
+func readConfigFiles(path string) ([]*configFile, error) {
+	main, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	includes, err := includePaths(main)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	var paths []string
+	layers := make(map[string]int)
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(dir, include)
+		}
+		if !strings.ContainsAny(include, "*?[") {
+			paths = append(paths, include)
+			continue
+		}
+		matches, err := filepath.Glob(include)
+		if err != nil {
+			return nil, parseError("%s: invalid include pattern '%s': %v", path, include, err)
+		}
+		paths = append(paths, matches...)
+	}
+	dropIns, err := filepath.Glob(filepath.Join(dir, dropInDir, "*.yaml"))
+	if err != nil {
+		return nil, initError("failed to list %s: %v", filepath.Join(dir, dropInDir), err)
+	}
+	for _, dropIn := range dropIns {
+		layers[dropIn] = layerDropIn
+	}
+	paths = append(paths, dropIns...)
+
+	files := []*configFile{main}
+	seen := map[string]struct{}{absPath(path): {}}
+	for _, p := range paths {
+		if _, ok := seen[absPath(p)]; ok {
+			continue
+		}
+		seen[absPath(p)] = struct{}{}
+
+		file, err := readConfigFile(p)
+		if err != nil {
+			return nil, err
+		}
+		file.layer = layerInclude
+		if layer, ok := layers[p]; ok {
+			file.layer = layer
+		}
+		if _, ok := file.settings[includeKey]; ok {
+			return nil, parseError("%s: %s is only allowed in the main config file %s", p, includeKey, path)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func readConfigFile(path string) (*configFile, error) {
+	format, err := getConfigFormatByExt(filepath.Ext(path))
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(string(format))
+	if err = v.ReadInConfig(); err != nil {
+		return nil, initError("read file error: %s", err.Error())
+	}
+	return &configFile{path: path, format: format, settings: v.AllSettings()}, nil
+}
+
+// includePaths removes the include key from the settings of the main file and returns its entries.
+func includePaths(main *configFile) ([]string, error) {
+	value, ok := main.settings[includeKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(main.settings, includeKey)
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, parseError("%s: %s must be a list of file paths", main.path, includeKey)
+	}
+	paths := make([]string, 0, len(items))
+	for _, item := range items {
+		path, ok := item.(string)
+		if !ok || path == "" {
+			return nil, parseError("%s: %s must be a list of file paths, got: %v", main.path, includeKey, item)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// mergeConfigFiles merges the settings of files in order and returns the merged settings and the files
+// every key was set in. Files are merged in layers: the main file, then the include files, then the conf.d
+// drop-ins, and a later layer overrides the values of earlier ones. Maps are merged key by key. Lists are
+// concatenated, a list item with a name (webhooks) replaces the item with the same name of an earlier layer.
+// Files of the same layer are siblings: a scalar or a named list item set by several siblings must be the same
+// everywhere, otherwise all conflicts are reported with the file and, for YAML, the line of each value.
+func mergeConfigFiles(files []*configFile) (map[string]any, map[string][]string, error) {
+	m := &merger{
+		merged:  make(map[string]any),
+		origins: make(map[string][]string),
+		names:   make(map[string]map[string]*configFile),
+		files:   make(map[string]*configFile),
+	}
+	for _, file := range files {
+		m.merge(m.merged, file.settings, "", file)
+	}
+	if len(m.conflicts) == 0 {
+		return m.merged, m.origins, nil
+	}
+
+	lines := make([]string, 0, len(m.conflicts))
+	for _, c := range m.conflicts {
+		if c.item != "" {
+			lines = append(lines, fmt.Sprintf("  %s: item '%s' defined in %s and %s",
+				c.key, c.item, c.first.path, c.second.path))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %v in %s, %v in %s",
+			c.key, c.firstValue, location(c.first, c.key), c.secondValue, location(c.second, c.key)))
+	}
+	return nil, nil, parseError("conflicting values in config files:\n%s", strings.Join(lines, "\n"))
+}
+
+type merger struct {
+	merged  map[string]any
+	origins map[string][]string
+	// names holds the file of every named list item by list key.
+	names     map[string]map[string]*configFile
+	files     map[string]*configFile
+	conflicts []conflict
+}
+
+func (m *merger) merge(dst, src map[string]any, prefix string, file *configFile) {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := joinKey(prefix, key)
+		value := src[key]
+
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			m.record(path, value, file)
+			continue
+		}
+
+		if dstMap, ok := existing.(map[string]any); ok {
+			if srcMap, ok := value.(map[string]any); ok {
+				m.merge(dstMap, srcMap, path, file)
+				continue
+			}
+		}
+		if dstList, ok := existing.([]any); ok {
+			if srcList, ok := value.([]any); ok {
+				replaced := m.recordItems(path, srcList, file)
+				dst[key] = append(withoutItems(dstList, replaced), srcList...)
+				m.origins[path] = append(m.origins[path], file.path)
+				continue
+			}
+		}
+		if fmt.Sprint(existing) == fmt.Sprint(value) {
+			continue
+		}
+		if first := m.files[path]; first.layer < file.layer {
+			m.forget(path)
+			dst[key] = value
+			m.record(path, value, file)
+			continue
+		}
+		m.conflicts = append(m.conflicts, conflict{
+			key:         path,
+			first:       m.files[path],
+			second:      file,
+			firstValue:  existing,
+			secondValue: value,
+		})
+	}
+}
+
+// record remembers the file of value and of every leaf of it.
+func (m *merger) record(path string, value any, file *configFile) {
+	m.files[path] = file
+	if values, ok := value.(map[string]any); ok {
+		for key, child := range values {
+			m.record(joinKey(path, key), child, file)
+		}
+		return
+	}
+	if items, ok := value.([]any); ok {
+		m.recordItems(path, items, file)
+	}
+	m.origins[path] = append(m.origins[path], file.path)
+}
+
+// forget drops the files of the value at path and of every leaf of it, before the value is overridden.
+func (m *merger) forget(path string) {
+	for key := range m.files {
+		if key == path || strings.HasPrefix(key, path+".") || strings.HasPrefix(key, path+"[") {
+			delete(m.files, key)
+			delete(m.origins, key)
+		}
+	}
+	for key := range m.names {
+		if key == path || strings.HasPrefix(key, path+".") {
+			delete(m.names, key)
+		}
+	}
+}
+
+// recordItems remembers the file of every named list item, reports names defined by two sibling files
+// and returns the names whose items of an earlier layer are replaced.
+func (m *merger) recordItems(path string, items []any, file *configFile) map[string]struct{} {
+	replaced := make(map[string]struct{})
+	for _, item := range items {
+		name := itemName(item)
+		if name == "" {
+			continue
+		}
+		if m.names[path] == nil {
+			m.names[path] = make(map[string]*configFile)
+		}
+		// Duplicates within one file are reported by Validate with the item indexes
+		first, exists := m.names[path][name]
+		switch {
+		case !exists || first == file:
+		case first.layer < file.layer:
+			replaced[name] = struct{}{}
+		default:
+			m.conflicts = append(m.conflicts, conflict{key: path, first: first, second: file, item: name})
+			continue
+		}
+		m.names[path][name] = file
+	}
+	return replaced
+}
+
+// withoutItems returns items without the named items listed in names.
+func withoutItems(items []any, names map[string]struct{}) []any {
+	if len(names) == 0 {
+		return items
+	}
+	kept := make([]any, 0, len(items))
+	for _, item := range items {
+		if _, ok := names[itemName(item)]; !ok {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// itemName returns the name of a list item, empty if the item has none.
+func itemName(item any) string {
+	values, ok := item.(map[string]any)
+	if !ok {
+		return ""
+	}
+	name, _ := values["name"].(string)
+	return name
+}
+
+// location returns "path:line" for keys of YAML files and the path otherwise.
+func location(file *configFile, key string) string {
+	if file.format != FormatYAML {
+		return file.path
+	}
+	root := parseYAMLNode(file.path)
+	if root == nil {
+		return file.path
+	}
+	if line := yamlKeyLine(root, key); line > 0 {
+		return fmt.Sprintf("%s:%d", file.path, line)
+	}
+	return file.path
+}
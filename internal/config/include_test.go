@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeConfigFiles(t *testing.T) {
+	main := &configFile{path: "config.yaml", layer: layerMain}
+	include := &configFile{path: "include.yaml", layer: layerInclude}
+	include2 := &configFile{path: "include2.yaml", layer: layerInclude}
+	dropIn := &configFile{path: "conf.d/10.yaml", layer: layerDropIn}
+	dropIn2 := &configFile{path: "conf.d/20.yaml", layer: layerDropIn}
+
+	tests := []struct {
+		name     string
+		files    []*configFile
+		settings []map[string]any
+		want     string
+		key      string
+		origin   string
+		conflict string
+	}{
+		{
+			name:     "drop-in overrides main file",
+			files:    []*configFile{main, dropIn},
+			settings: []map[string]any{{"logger": map[string]any{"level": "info", "format": "json"}}, {"logger": map[string]any{"level": "debug"}}},
+			want:     "map[logger:map[format:json level:debug]]",
+			key:      "logger.level",
+			origin:   "conf.d/10.yaml",
+		},
+		{
+			name:     "include overrides main file",
+			files:    []*configFile{main, include},
+			settings: []map[string]any{{"logger": map[string]any{"level": "info"}}, {"logger": map[string]any{"level": "warn"}}},
+			want:     "map[logger:map[level:warn]]",
+			key:      "logger.level",
+			origin:   "include.yaml",
+		},
+		{
+			name:     "drop-in overrides include",
+			files:    []*configFile{main, include, dropIn},
+			settings: []map[string]any{{}, {"logger": map[string]any{"level": "warn"}}, {"logger": map[string]any{"level": "debug"}}},
+			want:     "map[logger:map[level:debug]]",
+			key:      "logger.level",
+			origin:   "conf.d/10.yaml",
+		},
+		{
+			name:     "same value in sibling drop-ins",
+			files:    []*configFile{main, dropIn, dropIn2},
+			settings: []map[string]any{{}, {"logger": map[string]any{"level": "debug"}}, {"logger": map[string]any{"level": "debug"}}},
+			want:     "map[logger:map[level:debug]]",
+			key:      "logger.level",
+			origin:   "conf.d/10.yaml",
+		},
+		{
+			name:     "conflict between sibling drop-ins",
+			files:    []*configFile{main, dropIn, dropIn2},
+			settings: []map[string]any{{}, {"logger": map[string]any{"level": "debug"}}, {"logger": map[string]any{"level": "warn"}}},
+			conflict: "logger.level: debug in conf.d/10.yaml, warn in conf.d/20.yaml",
+		},
+		{
+			name:     "conflict between sibling includes",
+			files:    []*configFile{main, include, include2},
+			settings: []map[string]any{{}, {"logger": map[string]any{"level": "debug"}}, {"logger": map[string]any{"level": "warn"}}},
+			conflict: "logger.level: debug in include.yaml, warn in include2.yaml",
+		},
+		{
+			name:  "drop-in replaces a section set as scalar",
+			files: []*configFile{main, dropIn},
+			settings: []map[string]any{
+				{"logger": map[string]any{"options": "none"}},
+				{"logger": map[string]any{"options": map[string]any{"path": "/var/log/agent.log"}}},
+			},
+			want:   "map[logger:map[options:map[path:/var/log/agent.log]]]",
+			key:    "logger.options.path",
+			origin: "conf.d/10.yaml",
+		},
+		{
+			name:  "named list items are replaced by a later layer and added otherwise",
+			files: []*configFile{main, dropIn},
+			settings: []map[string]any{
+				{"webhooks": []any{map[string]any{"name": "a", "url": "old"}, map[string]any{"name": "b", "url": "b"}}},
+				{"webhooks": []any{map[string]any{"name": "a", "url": "new"}, map[string]any{"name": "c", "url": "c"}}},
+			},
+			want:   "map[webhooks:[map[name:b url:b] map[name:a url:new] map[name:c url:c]]]",
+			key:    "webhooks",
+			origin: "config.yaml, conf.d/10.yaml",
+		},
+		{
+			name:  "named list item in sibling drop-ins",
+			files: []*configFile{main, dropIn, dropIn2},
+			settings: []map[string]any{
+				{},
+				{"webhooks": []any{map[string]any{"name": "a", "url": "a"}}},
+				{"webhooks": []any{map[string]any{"name": "a", "url": "b"}}},
+			},
+			conflict: "webhooks: item 'a' defined in conf.d/10.yaml and conf.d/20.yaml",
+		},
+		{
+			name:     "unnamed list items are concatenated",
+			files:    []*configFile{main, include},
+			settings: []map[string]any{{"events": []any{"task.failed"}}, {"events": []any{"task.timed_out"}}},
+			want:     "map[events:[task.failed task.timed_out]]",
+			key:      "events",
+			origin:   "config.yaml, include.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, file := range tt.files {
+				file.settings = tt.settings[i]
+			}
+
+			merged, origins, err := mergeConfigFiles(tt.files)
+			if tt.conflict != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.conflict) {
+					t.Fatalf("mergeConfigFiles() error = %v, want conflict %q", err, tt.conflict)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeConfigFiles() error = %v", err)
+			}
+			if got := fmt.Sprint(merged); got != tt.want {
+				t.Errorf("merged = %s, want %s", got, tt.want)
+			}
+			if got := strings.Join(keyFiles(origins, tt.key), ", "); got != tt.origin {
+				t.Errorf("files of %s = %q, want %q", tt.key, got, tt.origin)
+			}
+		})
+	}
+}
+
+func TestLoadMergesIncludesAndDropIns(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": `version: 2
+include: [extra/*.yaml]
+logger:
+  level: info
+agent:
+  restart_options:
+    max_restarts: 3
+`,
+		"extra/restarts.yaml": `agent:
+  restart_options:
+    max_restarts: 4
+`,
+		"conf.d/10-debug.yaml": `logger:
+  level: debug
+`,
+		"conf.d/notes.txt": "not a drop-in",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := Load(filepath.Join(dir, "config.yaml"), "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Logger.Level != "debug" || cfg.Agent.RestartOptions.MaxRestarts != 4 {
+		t.Fatalf("level = %s, max_restarts = %d, want debug from the drop-in and 4 from the include",
+			cfg.Logger.Level, cfg.Agent.RestartOptions.MaxRestarts)
+	}
+	want := []string{
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(dir, "extra/restarts.yaml"),
+		filepath.Join(dir, "conf.d/10-debug.yaml"),
+	}
+	if got := cfg.Files(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Files() = %q, want %q", got, want)
+	}
+	for _, source := range cfg.Sources() {
+		if source.Key == "logger.level" && source.File != want[2] {
+			t.Fatalf("logger.level source = %+v, want the drop-in", source)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-ex-vm-agent/internal/fsutil"
+)
+
+const (
+	// versionKey is the top-level key with the layout version of a config file.
+	versionKey = "version"
+
+	// CurrentVersion is the config layout version produced by "config migrate". Files without
+	// a version key are treated as version 1.
+	CurrentVersion = 2
+)
+
+// migration upgrades the settings of a single file from version from to from+1 and returns deprecation warnings.
+type migration struct {
+	from    int
+	migrate func(settings map[string]any) []string
+}
+
+// migrations holds every migration ordered by version.
+var migrations = []migration{
+	{from: 1, migrate: migrateAgentsSection},
+}
+
+// legacyAgentKeys maps the keys of the flat "agents" section of version 1 to their current paths.
+var legacyAgentKeys = map[string]string{
+	"graceful_shutdown_agent_timeout":  "agent.graceful_shutdown_agent_timeout",
+	"graceful_shutdown_worker_timeout": "agent.graceful_shutdown_workers_timeout",
+	"max_task_timeout":                 "agent.task_options.max_task_timeout",
+	"max_task_count":                   "agent.task_options.max_task_count",
+	"stop_on_failure":                  "agent.task_options.stop_on_failure",
+	"restart_delay":                    "agent.restart_options.delay",
+	"max_restarts":                     "agent.restart_options.max_restarts",
+	"restart_on_failure":               "agent.restart_options.restart_on_failure",
+	"exponential_backoff":              "agent.restart_options.restart_exponent",
+}
+
+// MigrateResult describes a config file rewritten by MigrateFile.
+type MigrateResult struct {
+	// From is the version of the file before the migration.
+	From int
+
+	// To is the version of the file after the migration.
+	To int
+
+	// Backup is the path of the copy of the original file, empty if the file was already current.
+	Backup string
+
+	// Warnings lists every deprecated key that was moved.
+	Warnings []string
+}
+
+// migrateSettings removes the version key from the settings of file and upgrades them to CurrentVersion.
+// It returns the version of the file and the deprecation warnings of the applied migrations.
+func migrateSettings(file *configFile) (int, []string, error) {
+	version, err := fileVersion(file)
+	if err != nil {
+		return 0, nil, err
+	}
+	delete(file.settings, versionKey)
+
+	var warnings []string
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		for _, warning := range m.migrate(file.settings) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", file.path, warning))
+		}
+	}
+	return version, warnings, nil
+}
+
+// fileVersion returns the version declared by file, 1 if it has none.
+func fileVersion(file *configFile) (int, error) {
+	value, ok := file.settings[versionKey]
+	if !ok {
+		return 1, nil
+	}
+
+	var version int
+	switch v := value.(type) {
+	case int:
+		version = v
+	case int64:
+		version = int(v)
+	case float64:
+		version = int(v)
+	case string:
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, parseError("%s: %s must be an integer, got: '%s'", file.path, versionKey, v)
+		}
+		version = parsed
+	default:
+		return 0, parseError("%s: %s must be an integer, got: %v", file.path, versionKey, value)
+	}
+
+	if version < 1 || version > CurrentVersion {
+		return 0, parseError("%s: unsupported config version %d, this agent supports versions 1 to %d",
+			file.path, version, CurrentVersion)
+	}
+	return version, nil
+}
+
+// migrateAgentsSection moves the keys of the flat "agents" section of version 1 into the nested "agent" section.
+// A key that is set in both layouts keeps the current value. Unknown keys are moved as is, so the unknown key
+// check reports them under "agent".
+func migrateAgentsSection(settings map[string]any) []string {
+	legacy, ok := settings["agents"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	delete(settings, "agents")
+
+	var warnings []string
+	for key, value := range legacy {
+		target, known := legacyAgentKeys[key]
+		if !known {
+			target = joinKey("agent", key)
+		}
+		if !setIfAbsent(settings, target, value) {
+			warnings = append(warnings, fmt.Sprintf("agents.%s is deprecated and ignored, %s is already set", key, target))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("agents.%s is deprecated, use %s", key, target))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// setIfAbsent sets the value addressed by a dotted key path, creating intermediate maps,
+// and reports false if the key is already set.
+func setIfAbsent(settings map[string]any, path string, value any) bool {
+	keys := strings.Split(path, ".")
+	node := settings
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := node[key].(map[string]any)
+		if !ok {
+			if _, exists := node[key]; exists {
+				return false
+			}
+			child = make(map[string]any)
+			node[key] = child
+		}
+		node = child
+	}
+
+	last := keys[len(keys)-1]
+	if _, exists := node[last]; exists {
+		return false
+	}
+	node[last] = value
+	return true
+}
+
+// MigrateFile upgrades the config file at path to CurrentVersion in place. The original file is kept
+// next to it with a ".<timestamp>.bak" suffix. The file is rewritten in its own format from the parsed
+// values, so comments and key order are not preserved; references and include lists are kept as written.
+// A file that is already current is left untouched.
+func MigrateFile(path string) (*MigrateResult, error) {
+	file, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	version, warnings, err := migrateSettings(file)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrateResult{From: version, To: CurrentVersion, Warnings: warnings}
+	if version == CurrentVersion {
+		return result, nil
+	}
+
+	// The migrated file must pass the unknown key check, otherwise the result could not be loaded
+	settings := make(map[string]any, len(file.settings))
+	for key, value := range file.settings {
+		if key != includeKey {
+			settings[key] = value
+		}
+	}
+	if err = checkUnknownKeys(path, file.format, settings, Config{}); err != nil {
+		return nil, err
+	}
+
+	file.settings[versionKey] = CurrentVersion
+	data, err := encode(file.settings, file.format)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, initError("failed to stat %s: %v", path, err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, initError("read file error: %s", err.Error())
+	}
+	result.Backup = fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102-150405"))
+	if err = fsutil.WriteFileAtomic(result.Backup, original, info.Mode().Perm()); err != nil {
+		return nil, initError("failed to write backup %s: %v", result.Backup, err)
+	}
+	if err = fsutil.WriteFileAtomic(path, data, info.Mode().Perm()); err != nil {
+		return nil, initError("failed to write %s: %v", path, err)
+	}
+	return result, nil
+}
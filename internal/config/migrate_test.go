@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const legacyConfig = `agents:
+  max_task_timeout: 1m
+  max_restarts: 5
+  restart_on_failure: true
+agent:
+  restart_options:
+    max_restarts: 3
+`
+
+func TestMigrateSettingsMovesLegacyAgentKeys(t *testing.T) {
+	file := &configFile{path: "config.yaml", format: FormatYAML, settings: map[string]any{
+		"agents": map[string]any{
+			"max_task_timeout":   "1m",
+			"max_restarts":       5,
+			"restart_on_failure": true,
+		},
+		"agent": map[string]any{
+			"restart_options": map[string]any{"max_restarts": 3},
+		},
+	}}
+
+	version, warnings, err := migrateSettings(file)
+	if err != nil {
+		t.Fatalf("migrateSettings() error = %v", err)
+	}
+	if version != 1 {
+		t.Fatalf("version = %d, want 1", version)
+	}
+
+	agent := file.settings["agent"].(map[string]any)
+	restart := agent["restart_options"].(map[string]any)
+	tasks := agent["task_options"].(map[string]any)
+	if tasks["max_task_timeout"] != "1m" || restart["restart_on_failure"] != true {
+		t.Fatalf("agent = %v, want legacy keys moved", agent)
+	}
+	if restart["max_restarts"] != 3 {
+		t.Fatalf("max_restarts = %v, want the current value 3 kept", restart["max_restarts"])
+	}
+	if _, ok := file.settings["agents"]; ok {
+		t.Fatal("legacy agents section is kept")
+	}
+
+	want := []string{
+		"config.yaml: agents.max_restarts is deprecated and ignored, agent.restart_options.max_restarts is already set",
+		"config.yaml: agents.max_task_timeout is deprecated, use agent.task_options.max_task_timeout",
+		"config.yaml: agents.restart_on_failure is deprecated, use agent.restart_options.restart_on_failure",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("warnings = %q, want %q", warnings, want)
+	}
+}
+
+func TestMigrateSettingsRejectsUnsupportedVersion(t *testing.T) {
+	file := &configFile{path: "config.yaml", settings: map[string]any{versionKey: CurrentVersion + 1}}
+	if _, _, err := migrateSettings(file); err == nil || !strings.Contains(err.Error(), "unsupported config version") {
+		t.Fatalf("migrateSettings() error = %v, want unsupported version", err)
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(legacyConfig), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load() of version 1 error = %v", err)
+	}
+	if len(legacy.Warnings()) == 0 {
+		t.Fatal("Load() of version 1 returned no deprecation warnings")
+	}
+
+	result, err := MigrateFile(path)
+	if err != nil {
+		t.Fatalf("MigrateFile() error = %v", err)
+	}
+	if result.From != 1 || result.To != CurrentVersion || len(result.Warnings) != 3 {
+		t.Fatalf("result = %+v, want version 1 to %d with 3 warnings", result, CurrentVersion)
+	}
+	backup, err := os.ReadFile(result.Backup)
+	if err != nil || string(backup) != legacyConfig {
+		t.Fatalf("backup = %q, %v, want the original file", backup, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("migrated file mode = %v, %v, want 0640", info.Mode().Perm(), err)
+	}
+
+	migrated, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load() of migrated file error = %v", err)
+	}
+	if len(migrated.Warnings()) != 0 {
+		t.Fatalf("Load() of migrated file warnings = %q, want none", migrated.Warnings())
+	}
+	if migrated.Agent.TaskOptions.MaxTimeout != time.Minute || migrated.Agent.RestartOptions.MaxRestarts != 3 {
+		t.Fatalf("migrated agent = %+v, want max_task_timeout 1m and max_restarts 3", migrated.Agent)
+	}
+
+	again, err := MigrateFile(path)
+	if err != nil || again.Backup != "" || again.From != CurrentVersion {
+		t.Fatalf("second MigrateFile() = %+v, %v, want the current file untouched", again, err)
+	}
+}
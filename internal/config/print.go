@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// Encode returns the config in the given format using the same keys as the config file.
+// Values with ${env:..} and ${file:..} references are written as references, never resolved.
+func (c *Config) Encode(format Ext) ([]byte, error) {
+	values := toMap(*c)
+	c.restoreReferences(values)
+	values[versionKey] = CurrentVersion
+	return encode(values, format)
+}
+
+// encode writes values in the given format.
+func encode(values map[string]any, format Ext) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(values); err != nil {
+			return nil, parseError("yaml encoding error: %s", err.Error())
+		}
+		return buf.Bytes(), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, parseError("json encoding error: %s", err.Error())
+		}
+		return append(data, '\n'), nil
+	case FormatTOML:
+		data, err := toml.Marshal(values)
+		if err != nil {
+			return nil, parseError("toml encoding error: %s", err.Error())
+		}
+		return data, nil
+	default:
+		return nil, parseError("unsupported format: %s", format)
+	}
+}
+
+// toMap converts a config struct into nested maps keyed by mapstructure tags.
+// Durations are written as strings like "30s" so the output can be read back.
+func toMap(value any) map[string]any {
+	m, _ := toValue(reflect.ValueOf(value)).(map[string]any)
+	return m
+}
+
+func toValue(v reflect.Value) any {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toValue(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			m[name] = toValue(v.Field(i))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		items := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, toValue(v.Index(i)))
+		}
+		return items
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = toValue(iter.Value())
+		}
+		return m
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// profilesKey is the top-level key that holds named overlays of the base sections, e.g. profiles.prod.logger.level.
+const profilesKey = "profiles"
+
+// applyProfile removes the profiles section from settings and overlays the base sections with the named
+// profile. Maps are merged key by key, scalars and lists of the profile replace the base values.
+// An empty name only removes the section, an unknown one is an error listing the defined profiles.
+func applyProfile(settings map[string]any, name string) error {
+	value, ok := settings[profilesKey]
+	delete(settings, profilesKey)
+
+	profiles := map[string]any{}
+	if ok {
+		if profiles, ok = value.(map[string]any); !ok {
+			return parseError("%s must be a map of profile names to config sections", profilesKey)
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for profileName := range profiles {
+			names = append(names, profileName)
+		}
+		sort.Strings(names)
+		return initError("unknown profile '%s', defined profiles: %v", name, names)
+	}
+	// An empty profile is decoded as nil and overlays nothing
+	if profile == nil {
+		return nil
+	}
+	values, ok := profile.(map[string]any)
+	if !ok {
+		return parseError("profile '%s' must be a map of config sections", name)
+	}
+	overlay(settings, values)
+	return nil
+}
+
+// overlay merges src into dst, values of src win.
+func overlay(dst, src map[string]any) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]any); ok {
+			if dstMap, ok := dst[key].(map[string]any); ok {
+				overlay(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// collectProfileKeys checks the keys of every profile against the structure of t,
+// unknown keys are reported as "profiles.<name>.<key>".
+func collectProfileKeys(value any, t reflect.Type, unknown *[]unknownKey) {
+	profiles, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for name, profile := range profiles {
+		collectUnknownKeys(profile, t, joinKey(profilesKey, name), unknown)
+	}
+}
+
+// profileKey returns the path of key inside the named profile.
+func profileKey(profile, key string) string {
+	return joinKey(joinKey(profilesKey, profile), key)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    string
+		err     string
+	}{
+		{
+			name:    "maps are merged key by key, scalars and lists are replaced",
+			profile: "prod",
+			want:    "map[logger:map[format:json level:warn] notifier:map[webhooks:[ops]]]",
+		},
+		{
+			name:    "empty profile overlays nothing",
+			profile: "empty",
+			want:    "map[logger:map[format:json level:info] notifier:map[webhooks:[dev ops]]]",
+		},
+		{
+			name: "no profile only removes the section",
+			want: "map[logger:map[format:json level:info] notifier:map[webhooks:[dev ops]]]",
+		},
+		{
+			name:    "unknown profile",
+			profile: "stage",
+			err:     "unknown profile 'stage', defined profiles: [empty prod]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := map[string]any{
+				"logger":   map[string]any{"level": "info", "format": "json"},
+				"notifier": map[string]any{"webhooks": []any{"dev", "ops"}},
+				profilesKey: map[string]any{
+					"prod": map[string]any{
+						"logger":   map[string]any{"level": "warn"},
+						"notifier": map[string]any{"webhooks": []any{"ops"}},
+					},
+					"empty": nil,
+				},
+			}
+
+			err := applyProfile(settings, tt.profile)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("applyProfile() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyProfile() error = %v", err)
+			}
+			if got := fmt.Sprint(settings); got != tt.want {
+				t.Fatalf("settings = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfileRejectsInvalidSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles any
+		err      string
+	}{
+		{name: "profiles is not a map", profiles: []any{"prod"}, err: "profiles must be a map"},
+		{name: "profile is not a map", profiles: map[string]any{"prod": "warn"}, err: "profile 'prod' must be a map"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyProfile(map[string]any{profilesKey: tt.profiles}, "prod")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("applyProfile() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadAppliesProfile(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `version: 2
+logger:
+  level: info
+agent:
+  restart_options:
+    max_restarts: 3
+profiles:
+  prod:
+    logger:
+      level: warn
+`)
+
+	cfg, err := Load(path, "prod")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile() != "prod" || cfg.Logger.Level != "warn" || cfg.Agent.RestartOptions.MaxRestarts != 3 {
+		t.Fatalf("profile = %s, level = %s, max_restarts = %d, want prod, warn and 3",
+			cfg.Profile(), cfg.Logger.Level, cfg.Agent.RestartOptions.MaxRestarts)
+	}
+
+	sources := keySources(cfg)
+	if got := sources["logger.level"]; got.Source != SourceProfile || got.File != path {
+		t.Errorf("logger.level source = %+v, want profile in %s", got, path)
+	}
+	if got := sources["agent.restart_options.max_restarts"]; got.Source != SourceFile {
+		t.Errorf("agent.restart_options.max_restarts source = %+v, want file", got)
+	}
+
+	if _, err := Load(path, "stage"); err == nil || !strings.Contains(err.Error(), "defined profiles: [prod]") {
+		t.Fatalf("Load() with unknown profile error = %v, want the defined profiles listed", err)
+	}
+}
+
+// keySources returns the sources of cfg by key.
+func keySources(cfg *Config) map[string]KeySource {
+	sources := make(map[string]KeySource)
+	for _, source := range cfg.Sources() {
+		sources[source.Key] = source
+	}
+	return sources
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// referencePattern matches ${kind:argument|modifier|...} references in string values,
+// e.g. ${env:WEBHOOK_TOKEN}, ${file:/run/secrets/token} or ${file:/run/secrets/token|trim}.
+var referencePattern = regexp.MustCompile(`\$\{([a-z]+):([^}|]*)((?:\|[a-z]+)*)\}`)
+
+// reference is a config value that contains references, resolved by Load.
+type reference struct {
+	// raw is the value as written in the config file.
+	raw string
+
+	// resolved is the value with every reference replaced.
+	resolved string
+}
+
+// resolveReferences replaces ${env:NAME} and ${file:PATH} references in the string values of settings with
+// the environment variable and the file content. The "trim" modifier strips leading and trailing whitespace,
+// e.g. the trailing newline of a secret file. References are keyed by the key path, so the raw value can be
+// shown instead of the resolved one. Every unresolved reference is reported in a single *ValidationError.
+func resolveReferences(settings map[string]any) (map[string]reference, error) {
+	references := make(map[string]reference)
+	result := &ValidationError{}
+	resolveValue(settings, "", references, result)
+
+	if len(result.Fields) == 0 {
+		return references, nil
+	}
+	sort.Slice(result.Fields, func(i, j int) bool {
+		return result.Fields[i].Path < result.Fields[j].Path
+	})
+	return nil, result
+}
+
+func resolveValue(value any, path string, references map[string]reference, result *ValidationError) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = resolveValue(child, joinKey(path, key), references, result)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = resolveValue(item, path+"["+strconv.Itoa(i)+"]", references, result)
+		}
+	case string:
+		if !referencePattern.MatchString(v) {
+			return v
+		}
+		resolved := referencePattern.ReplaceAllStringFunc(v, func(match string) string {
+			parts := referencePattern.FindStringSubmatch(match)
+			value, err := resolveReference(parts[1], parts[2], parts[3])
+			if err != nil {
+				result.add(path, "%s: %v", match, err)
+				return match
+			}
+			return value
+		})
+		references[path] = reference{raw: v, resolved: resolved}
+		return resolved
+	}
+	return value
+}
+
+// resolveReference returns the value of a single reference. The error never contains the value.
+func resolveReference(kind, argument, modifiers string) (string, error) {
+	if argument == "" {
+		return "", fmt.Errorf("empty %s reference", kind)
+	}
+
+	var value string
+	switch kind {
+	case "env":
+		env, ok := os.LookupEnv(argument)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", argument)
+		}
+		value = env
+	case "file":
+		data, err := os.ReadFile(argument)
+		if err != nil {
+			return "", fmt.Errorf("cannot read file: %v", err)
+		}
+		value = string(data)
+	default:
+		return "", fmt.Errorf("unknown reference kind '%s', allowed kinds: env, file", kind)
+	}
+
+	for _, modifier := range strings.Split(strings.TrimPrefix(modifiers, "|"), "|") {
+		switch modifier {
+		case "":
+		case "trim":
+			value = strings.TrimSpace(value)
+		default:
+			return "", fmt.Errorf("unknown modifier '%s', allowed modifiers: trim", modifier)
+		}
+	}
+	return value, nil
+}
+
+// displayValue returns the raw value of a key that contains references, so resolved secrets
+// do not end up in error messages, and value otherwise.
+func (c *Config) displayValue(path string, value any) any {
+	if ref, ok := c.references[path]; ok {
+		return ref.raw
+	}
+	return value
+}
+
+// redact replaces the resolved values of references in text with their raw values. Messages of component
+// validators and of the decoder carry no key path, so values are matched by content, the longest first.
+// Every resolved value is redacted however short it is; a match must be a whole token, so a short value
+// like "info" is not replaced inside an unrelated word like "information".
+func (c *Config) redact(text string) string {
+	refs := make([]reference, 0, len(c.references))
+	for _, ref := range c.references {
+		if ref.resolved != "" && ref.resolved != ref.raw {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return text
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return len(refs[i].resolved) > len(refs[j].resolved)
+	})
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		replaced := false
+		for _, ref := range refs {
+			if strings.HasPrefix(text[i:], ref.resolved) && isWholeToken(text, i, i+len(ref.resolved)) {
+				b.WriteString(ref.raw)
+				i += len(ref.resolved)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			b.WriteString(text[i : i+size])
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// isWholeToken reports whether text[start:end] does not continue a word on either side: a match that starts
+// or ends with a letter, a digit or "_" must not be preceded or followed by one.
+func isWholeToken(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:end])
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isTokenRune(first) && isTokenRune(before) {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(text[start:end])
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isTokenRune(last) && isTokenRune(after) {
+		return false
+	}
+	return true
+}
+
+func isTokenRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// restoreReferences puts the raw values of references back into values produced by toMap.
+func (c *Config) restoreReferences(values map[string]any) {
+	for path, ref := range c.references {
+		setPath(values, path, ref.raw)
+	}
+}
+
+// setPath sets the value addressed by a dotted key path like "notifier.webhooks[0].secret".
+func setPath(values map[string]any, path string, value any) {
+	parts := strings.Split(path, ".")
+	var node any = values
+	for i, part := range parts {
+		key, indexes := splitIndexes(part)
+		m, ok := node.(map[string]any)
+		if !ok {
+			return
+		}
+		if i == len(parts)-1 && len(indexes) == 0 {
+			if _, exists := m[key]; exists {
+				m[key] = value
+			}
+			return
+		}
+		node = m[key]
+		for j, index := range indexes {
+			items, ok := node.([]any)
+			if !ok || index >= len(items) {
+				return
+			}
+			if i == len(parts)-1 && j == len(indexes)-1 {
+				items[index] = value
+				return
+			}
+			node = items[index]
+		}
+	}
+}
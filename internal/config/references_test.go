@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	cfg := &Config{references: map[string]reference{
+		"notifier.webhooks[0].secret": {raw: "${env:TOKEN}", resolved: "abc"},
+		"logger.level":                {raw: "${env:LEVEL}", resolved: "info"},
+		"notifier.webhooks[0].url":    {raw: "${env:URL}", resolved: "https://example.com/abc"},
+		"notifier.queue_dir":          {raw: "/var/lib/vm-agent", resolved: "/var/lib/vm-agent"},
+	}}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "token abc rejected", want: "token ${env:TOKEN} rejected"},
+		{text: `invalid duration "abc"`, want: `invalid duration "${env:TOKEN}"`},
+		{text: "abc", want: "${env:TOKEN}"},
+		{text: "abcdef and xabc stay", want: "abcdef and xabc stay"},
+		{text: "information about info", want: "information about ${env:LEVEL}"},
+		{text: "url https://example.com/abc is unreachable", want: "url ${env:URL} is unreachable"},
+		{text: "dir /var/lib/vm-agent", want: "dir /var/lib/vm-agent"},
+	}
+	for _, tt := range tests {
+		if got := cfg.redact(tt.text); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLoadKeepsResolvedValuesOutOfErrors(t *testing.T) {
+	t.Setenv("TEST_RESTART_DELAY", "abc")
+	t.Setenv("TEST_LOG_LEVEL", "dbg")
+	t.Setenv("TEST_WEBHOOK_NAME", "a b")
+	path := writeConfig(t, "config.yaml", `version: 2
+agent:
+  restart_options:
+    delay: ${env:TEST_RESTART_DELAY}
+`)
+
+	_, err := Load(path, "")
+	if err == nil || strings.Contains(err.Error(), "abc") {
+		t.Fatalf("Load() error = %v, want no resolved value", err)
+	}
+
+	path = writeConfig(t, "config.yaml", `version: 2
+logger:
+  level: ${env:TEST_LOG_LEVEL}
+`)
+	_, err = Load(path, "")
+	if err == nil || strings.Contains(err.Error(), "dbg") || !strings.Contains(err.Error(), "${env:TEST_LOG_LEVEL}") {
+		t.Fatalf("Load() error = %v, want the reference instead of the value", err)
+	}
+
+	path = writeConfig(t, "config.yaml", `version: 2
+notifier:
+  webhooks:
+    - name: ${env:TEST_WEBHOOK_NAME}
+      url: https://example.com/hook
+`)
+	_, err = Load(path, "")
+	if err == nil || strings.Contains(err.Error(), "a b") || !strings.Contains(err.Error(), "${env:TEST_WEBHOOK_NAME}") {
+		t.Fatalf("Load() error = %v, want the reference instead of the value", err)
+	}
+}
+
+func TestEncodeWritesReferences(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("s3c\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_WEBHOOK_URL", "https://example.com/hook-token")
+	path := writeConfig(t, "config.yaml", `version: 2
+notifier:
+  webhooks:
+    - name: alerts
+      url: ${env:TEST_WEBHOOK_URL}
+      secret: ${file:`+secret+`|trim}
+`)
+
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.Notifier.Webhooks[0].Secret; got != "s3c" {
+		t.Fatalf("secret = %q, want the trimmed file content", got)
+	}
+	for _, format := range []Ext{FormatYAML, FormatJSON, FormatTOML} {
+		data, err := cfg.Encode(format)
+		if err != nil {
+			t.Fatalf("Encode(%s) error = %v", format, err)
+		}
+		out := string(data)
+		if strings.Contains(out, "s3c") || strings.Contains(out, "hook-token") {
+			t.Errorf("Encode(%s) shows resolved values:\n%s", format, out)
+		}
+		if !strings.Contains(out, "${env:TEST_WEBHOOK_URL}") || !strings.Contains(out, "${file:"+secret+"|trim}") {
+			t.Errorf("Encode(%s) does not show the references:\n%s", format, out)
+		}
+	}
+}
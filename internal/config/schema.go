@@ -0,0 +1,281 @@
+package config
+
+//go:generate go run gen_descriptions.go
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	agent "go-ex-vm-agent"
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/logger"
+	"go-ex-vm-agent/internal/notifier"
+)
+
+const (
+	// schemaDraft is the JSON Schema dialect of the generated schema.
+	schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+	// durationPattern matches values accepted by time.ParseDuration.
+	durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+	// referenceSchemaPattern matches values that contain ${env:..} or ${file:..} references, see referencePattern.
+	referenceSchemaPattern = `\$\{(env|file):[^}|]+(\|trim)*\}`
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// itemDefaults holds the defaults of list items, which defaultConfig cannot express.
+var itemDefaults = map[reflect.Type]any{
+	reflect.TypeOf(notifierWebhook{}): toMap(defaultWebhook()),
+}
+
+// Schema returns the JSON Schema of the config file. Keys are the mapstructure names, constraints come
+// from validate tags, defaults from defaultConfig and descriptions from the doc comments of the fields.
+func Schema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}), toMap(defaultConfig()))
+	schema["$schema"] = schemaDraft
+	schema["title"] = "vm-agent configuration"
+	schema["properties"].(map[string]any)[includeKey] = map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": "Config files merged into this one, relative to its directory, globs are allowed. Only allowed in the main config file.",
+	}
+	schema["properties"].(map[string]any)[versionKey] = map[string]any{
+		"type":        "integer",
+		"minimum":     1,
+		"maximum":     CurrentVersion,
+		"default":     CurrentVersion,
+		"description": "Layout version of the config file, older layouts are migrated on load and by 'config migrate'.",
+	}
+	schema["properties"].(map[string]any)[profilesKey] = map[string]any{
+		"type":                 "object",
+		"additionalProperties": profileSchema(),
+		"description":          "Named overlays of the base sections, selected by --profile or " + agent.EnvPrefix + "_PROFILE.",
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, parseError("schema encoding error: %s", err.Error())
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor builds the schema of type t. defaults holds the default value of t, nil if it has none.
+func schemaFor(t reflect.Type, defaults any) map[string]any {
+	if t == durationType {
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), defaults)
+	case reflect.Struct:
+		return structSchema(t, defaults)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), itemDefaults[t.Elem()])}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), nil)}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+func structSchema(t reflect.Type, defaults any) map[string]any {
+	defaultValues, _ := defaults.(map[string]any)
+	properties := make(map[string]any, t.NumField())
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaFor(field.Type, defaultValues[name])
+		if description := fieldDescriptions[t.Name()+"."+field.Name]; description != "" {
+			property["description"] = description
+		}
+		if value, ok := defaultValues[name]; ok && field.Type.Kind() != reflect.Struct && !isZeroDefault(value) {
+			property["default"] = value
+		}
+		if applyValidateTag(property, field.Type, field.Tag.Get("validate")) {
+			required = append(required, name)
+		}
+		properties[name] = allowReferences(property)
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyValidateTag translates validate tag rules into schema keywords and reports whether the field is required.
+// Rules after "dive" apply to the items of a list.
+func applyValidateTag(property map[string]any, t reflect.Type, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	rules, itemRules, dive := strings.Cut(tag, "dive")
+	if dive {
+		if items, ok := property["items"].(map[string]any); ok {
+			applyRules(items, t.Elem(), strings.Trim(itemRules, ","))
+		}
+	}
+	return applyRules(property, t, strings.Trim(rules, ","))
+}
+
+func applyRules(property map[string]any, t reflect.Type, rules string) bool {
+	required, omitempty := false, false
+	constraints := make(map[string]any)
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "omitempty":
+			omitempty = true
+		case "min", "max":
+			keyword := map[string]string{"min": "minimum", "max": "maximum"}[name]
+			if t == durationType {
+				// JSON Schema cannot compare duration strings, the bounds are kept as annotations
+				constraints["x-"+keyword+"-duration"] = param
+			} else if value, err := strconv.Atoi(param); err == nil {
+				constraints[keyword] = value
+			}
+		case "http_url":
+			constraints["format"] = "uri"
+			constraints["pattern"] = "^https?://"
+		case "webhook_name":
+			constraints["pattern"] = notifier.WebhookNamePattern
+		case "log_level":
+			constraints["enum"] = logger.ValidLogLevels
+		case "log_format":
+			constraints["enum"] = logger.ValidLogFormats
+		case "log_output":
+			constraints["enum"] = logger.ValidLogOutputs
+		case "event_type":
+			constraints["enum"] = events.ValidEventTypes
+		}
+	}
+
+	// With omitempty the zero value means "use the default" and must pass as well
+	_, hasMinimum := constraints["minimum"]
+	if omitempty && (hasMinimum || constraints["enum"] != nil) && t != durationType {
+		property["anyOf"] = []any{map[string]any{"const": reflect.Zero(t).Interface()}, constraints}
+		return required
+	}
+
+	for keyword, value := range constraints {
+		property[keyword] = value
+	}
+	return required
+}
+
+// allowReferences lets every scalar value of property, including list items and map values, be a string
+// with references: references are resolved before the value is decoded and validated. The constraints of
+// the value move to the first alternative of anyOf, descriptions, defaults and annotations stay in place.
+func allowReferences(property map[string]any) map[string]any {
+	switch property["type"] {
+	case "array":
+		if items, ok := property["items"].(map[string]any); ok {
+			property["items"] = allowReferences(items)
+		}
+		return property
+	case "object":
+		if values, ok := property["additionalProperties"].(map[string]any); ok {
+			property["additionalProperties"] = allowReferences(values)
+		}
+		return property
+	case "string":
+		// A string without constraints accepts references as is
+		if len(property) == len(annotations(property))+1 {
+			return property
+		}
+	}
+
+	wrapped := annotations(property)
+	value := make(map[string]any, len(property))
+	for keyword, v := range property {
+		if _, ok := wrapped[keyword]; !ok {
+			value[keyword] = v
+		}
+	}
+	wrapped["anyOf"] = []any{value, map[string]any{"type": "string", "pattern": referenceSchemaPattern}}
+	return wrapped
+}
+
+// annotations returns the keywords of property that describe the value rather than constrain it.
+func annotations(property map[string]any) map[string]any {
+	result := make(map[string]any)
+	for keyword, value := range property {
+		if keyword == "description" || keyword == "default" || strings.HasPrefix(keyword, "x-") {
+			result[keyword] = value
+		}
+	}
+	return result
+}
+
+// profileSchema returns the schema of a profile: the config sections without defaults and required keys,
+// since a profile sets only the values that differ from the base sections.
+func profileSchema() map[string]any {
+	schema := schemaFor(reflect.TypeOf(Config{}), nil)
+	dropRequired(schema)
+	return schema
+}
+
+func dropRequired(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		delete(v, "required")
+		for key, child := range v {
+			// Lists of a profile replace the base lists, so their items stay complete
+			if key != "items" {
+				dropRequired(child)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			dropRequired(item)
+		}
+	}
+}
+
+func isZeroDefault(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return v == "" || v == "0s"
+	case int:
+		return v == 0
+	case bool:
+		return !v
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return value == nil
+	}
+}
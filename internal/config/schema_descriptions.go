@@ -0,0 +1,51 @@
+// Code generated by gen_descriptions.go; DO NOT EDIT.
+
+package config
+
+// fieldDescriptions holds doc comments of config struct fields keyed by "type.Field".
+var fieldDescriptions = map[string]string{
+	"Config.Agent":                              "Represents the configuration settings for the agent.",
+	"Config.Logger":                             "Represents the configuration for the logging system.",
+	"Config.Notifier":                           "Represents the configuration for delivering task and runner events to webhooks.",
+	"agentConfig.PIDFile":                       "Specifies the pidfile used as a lock that prevents several agents from running on the same host.",
+	"agentConfig.RestartOptions":                "Defines the restart configuration options for the agent.",
+	"agentConfig.RunnerTimeout":                 "Specifies the duration allowed for the agent to shut down gracefully before being forcefully terminated.",
+	"agentConfig.StateDir":                      "Specifies the directory where the agent persists its state between process restarts.",
+	"agentConfig.TaskOptions":                   "Defines settings for task execution.",
+	"agentConfig.UpgradeTimeout":                "Specifies how long the new binary may take to report readiness during a zero-downtime upgrade.",
+	"agentConfig.WorkersTimeout":                "Specifies the duration to wait for workers to complete during a graceful shutdown process.",
+	"agentRestartOptions.DegradedMode":          "Determines whether the agent keeps essential tasks running once restarts are exhausted.",
+	"agentRestartOptions.DegradedRetryInterval": "Specifies how often a full restart is attempted while the agent is degraded.",
+	"agentRestartOptions.Delay":                 "Specifies the time duration to wait before attempting an agent restart.",
+	"agentRestartOptions.MaxDelay":              "Specifies the upper bound of the restart delay when exponential backoff is enabled.",
+	"agentRestartOptions.MaxRestarts":           "Specifies the maximum number of times the agent will attempt to restart.",
+	"agentRestartOptions.RestartExponent":       "Determines whether an exponential backoff delay strategy is applied between restart attempts.",
+	"agentRestartOptions.RestartOnFailure":      "Determines whether the agent should automatically restart upon encountering a failure or crash.",
+	"agentRestartOptions.StableWindow":          "Specifies the uptime after which the restart counter is reset.",
+	"agentTaskOptions.HistoryFile":              "Specifies the file where task execution history is persisted across agent restarts.",
+	"agentTaskOptions.HistorySize":              "Specifies how many of the latest executions are kept in the history of each task.",
+	"agentTaskOptions.MaxCount":                 "Specifies the maximum number of tasks that can be executed concurrently.",
+	"agentTaskOptions.MaxTimeout":               "Specifies the maximum duration allowed for a task to execute before it is forcibly terminated.",
+	"agentTaskOptions.StopOnFailure":            "Determines if task execution should stop when a failure is encountered.",
+	"loggerConfig.FileOptions":                  "Defines file-specific configuration options for logger output, such as file path, size, age, and backups.",
+	"loggerConfig.Format":                       "Specifies the format of the logs (e.g., JSON, plain text).",
+	"loggerConfig.Level":                        "Specifies the logging level for the logger configuration.",
+	"loggerConfig.Output":                       "Specifies the destination where log messages should be written (e.g., file, stdout, stderr).",
+	"loggerFileOptions.Compress":                "Indicates whether old log files should be compressed using gzip.",
+	"loggerFileOptions.FilePath":                "Specifies the file path where the log file will be stored.",
+	"loggerFileOptions.MaxAge":                  "Specifies the maximum number of days logs will be retained before being automatically deleted.",
+	"loggerFileOptions.MaxBackups":              "Specifies the maximum number of backup log files to retain.",
+	"loggerFileOptions.MaxSize":                 "Specifies the maximum size (in MB) of the log file before it is rotated.",
+	"notifierConfig.QueueDir":                   "Specifies the directory where undelivered events are kept while an endpoint is down.",
+	"notifierConfig.QueueSize":                  "Specifies the maximum number of undelivered events kept per webhook.",
+	"notifierConfig.Webhooks":                   "Defines the HTTP endpoints that receive task and runner events.",
+	"notifierWebhook.Events":                    "Specifies the event types sent to the webhook, all events are sent when empty.",
+	"notifierWebhook.Headers":                   "Specifies additional HTTP headers sent with every request.",
+	"notifierWebhook.MaxRetries":                "Specifies how many times a failed delivery is retried, 0 means the default and -1 disables retries.",
+	"notifierWebhook.MaxRetryDelay":             "Specifies the upper bound of the delay between retries.",
+	"notifierWebhook.Name":                      "Specifies the unique webhook name used in logs and as the queue subdirectory.",
+	"notifierWebhook.RetryDelay":                "Specifies the delay before the first retry, doubled on every next retry.",
+	"notifierWebhook.Secret":                    "Specifies the key used to sign request bodies with HMAC-SHA256.",
+	"notifierWebhook.Timeout":                   "Specifies the timeout of a single HTTP request.",
+	"notifierWebhook.URL":                       "Specifies the endpoint that receives event payloads via HTTP POST.",
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestSchemaAllowsReferences(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema() is not JSON: %v", err)
+	}
+	reference := fmt.Sprint(map[string]any{"type": "string", "pattern": referenceSchemaPattern})
+
+	tests := []struct {
+		name       string
+		path       []string
+		references bool
+	}{
+		{name: "enum", path: []string{"properties", "logger", "properties", "level"}, references: true},
+		{name: "integer", path: []string{"properties", "agent", "properties", "restart_options", "properties", "max_restarts"}, references: true},
+		{name: "duration", path: []string{"properties", "agent", "properties", "restart_options", "properties", "delay"}, references: true},
+		{name: "list item", path: []string{"properties", "notifier", "properties", "webhooks", "items", "properties", "events", "items"}, references: true},
+		{name: "boolean", path: []string{"properties", "logger", "properties", "options", "properties", "compress"}, references: true},
+		{name: "profile value", path: []string{"properties", "profiles", "additionalProperties", "properties", "logger", "properties", "level"}, references: true},
+		{name: "plain string", path: []string{"properties", "notifier", "properties", "webhooks", "items", "properties", "secret"}},
+		{name: "map value", path: []string{"properties", "notifier", "properties", "webhooks", "items", "properties", "headers", "additionalProperties"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property := schemaProperty(t, schema, tt.path)
+			anyOf, _ := property["anyOf"].([]any)
+			allowed := len(anyOf) == 2 && fmt.Sprint(anyOf[1]) == reference
+			if allowed != tt.references {
+				t.Fatalf("property = %v, want references in anyOf: %t", property, tt.references)
+			}
+			if property["type"] != nil && tt.references {
+				t.Fatalf("property = %v, want the type moved into anyOf", property)
+			}
+		})
+	}
+
+	if _, ok := schemaProperty(t, schema, []string{"properties", "logger", "properties", "level"})["description"]; !ok {
+		t.Error("logger.level has no description next to anyOf")
+	}
+	for _, key := range []string{includeKey, versionKey, profilesKey} {
+		if _, ok := schemaProperty(t, schema, []string{"properties", key})["description"]; !ok {
+			t.Errorf("%s has no description", key)
+		}
+	}
+}
+
+// schemaProperty returns the schema at path.
+func schemaProperty(t *testing.T, schema map[string]any, path []string) map[string]any {
+	t.Helper()
+
+	value := schema
+	for _, key := range path {
+		next, ok := value[key].(map[string]any)
+		if !ok {
+			t.Fatalf("schema has no %v", path)
+		}
+		value = next
+	}
+	return value
+}
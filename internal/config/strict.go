@@ -0,0 +1,268 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// unknownKey describes a configuration key that does not map to any Config field.
+type unknownKey struct {
+	// Path is the full dotted key path, list items are addressed as "webhooks[0]".
+	Path string
+
+	// Line is the line number of the key in the config file, 0 when the format does not allow to find it.
+	Line int
+
+	// Suggestion is the closest valid key at the same level, empty when nothing is close enough.
+	Suggestion string
+}
+
+func (k unknownKey) String() string {
+	var b strings.Builder
+	b.WriteString(k.Path)
+	if k.Line > 0 {
+		fmt.Fprintf(&b, " (line %d)", k.Line)
+	}
+	if k.Suggestion != "" {
+		fmt.Fprintf(&b, ": did you mean '%s'?", k.Suggestion)
+	}
+	return b.String()
+}
+
+// checkUnknownKeys compares the decoded settings with the keys declared by mapstructure tags of target
+// and returns an error listing every unknown key with its line number and the closest valid key.
+// Profiles are checked against the same structure as the base sections.
+func checkUnknownKeys(path string, format Ext, settings map[string]any, target any) error {
+	t := reflect.TypeOf(target)
+	base := make(map[string]any, len(settings))
+	for key, value := range settings {
+		if key != profilesKey {
+			base[key] = value
+		}
+	}
+
+	var unknown []unknownKey
+	collectUnknownKeys(base, t, "", &unknown)
+	collectProfileKeys(settings[profilesKey], t, &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	if format == FormatYAML {
+		if root := parseYAMLNode(path); root != nil {
+			for i := range unknown {
+				unknown[i].Line = yamlKeyLine(root, unknown[i].Path)
+			}
+		}
+	}
+
+	sort.Slice(unknown, func(i, j int) bool {
+		if unknown[i].Line != unknown[j].Line {
+			return unknown[i].Line < unknown[j].Line
+		}
+		return unknown[i].Path < unknown[j].Path
+	})
+
+	lines := make([]string, 0, len(unknown))
+	for _, key := range unknown {
+		lines = append(lines, "  "+key.String())
+	}
+	return parseError("unknown keys in %s:\n%s", path, strings.Join(lines, "\n"))
+}
+
+// collectUnknownKeys walks value along the structure of t. Maps and scalar fields are leaves,
+// their content is not checked.
+func collectUnknownKeys(value any, t reflect.Type, prefix string, unknown *[]unknownKey) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		settings, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := structKeys(t)
+		for key, child := range settings {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*unknown = append(*unknown, unknownKey{
+					Path:       joinKey(prefix, key),
+					Suggestion: closestKey(strings.ToLower(key), fields),
+				})
+				continue
+			}
+			collectUnknownKeys(child, field, joinKey(prefix, key), unknown)
+		}
+
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			collectUnknownKeys(item, t.Elem(), prefix+"["+strconv.Itoa(i)+"]", unknown)
+		}
+	}
+}
+
+// structKeys returns the field types of t by their mapstructure key.
+func structKeys(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// closestKey returns the valid key with the smallest edit distance to key, or an empty string
+// when even the closest one differs too much to be a typo.
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", -1
+	for candidate := range fields {
+		distance := levenshtein(key, candidate)
+		if bestDistance < 0 || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	if best != "" && bestDistance <= max(2, len(key)/3) {
+		return best
+	}
+
+	// Keys with extra words like "restart_delay" for "delay" are common, so a candidate
+	// whose words all appear in the key is accepted even if the edit distance is large.
+	best = ""
+	for candidate := range fields {
+		if containsWords(key, candidate) && len(candidate) > len(best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// containsWords reports whether every "_" separated word of candidate is present in key.
+func containsWords(key, candidate string) bool {
+	words := make(map[string]struct{})
+	for _, word := range strings.Split(key, "_") {
+		words[word] = struct{}{}
+	}
+	for _, word := range strings.Split(candidate, "_") {
+		if _, ok := words[word]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// parseYAMLNode parses the config file into a yaml node tree, nil if it cannot be read.
+func parseYAMLNode(path string) *yaml.Node {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+	return &root
+}
+
+// yamlKeyLine returns the line of the key addressed by a dotted path, 0 if it is not found.
+func yamlKeyLine(root *yaml.Node, path string) int {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := 0
+	for _, part := range strings.Split(path, ".") {
+		key, indexes := splitIndexes(part)
+
+		if node.Kind != yaml.MappingNode {
+			return 0
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				line = node.Content[i].Line
+				node = node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+
+		for _, index := range indexes {
+			if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				return 0
+			}
+			node = node.Content[index]
+			line = node.Line
+		}
+	}
+	return line
+}
+
+// splitIndexes splits "webhooks[0]" into "webhooks" and [0].
+func splitIndexes(part string) (string, []int) {
+	key, rest, found := strings.Cut(part, "[")
+	if !found {
+		return part, nil
+	}
+
+	var indexes []int
+	for _, chunk := range strings.Split(rest, "[") {
+		index, err := strconv.Atoi(strings.TrimSuffix(chunk, "]"))
+		if err != nil {
+			return key, indexes
+		}
+		indexes = append(indexes, index)
+	}
+	return key, indexes
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `version: 2
+agent:
+  restart_options:
+    restart_delay: 5s
+  task_options:
+    max_task_timout: 1m
+    stop_on_failure: true
+loger:
+  level: info
+`)
+
+	_, err := Load(path, "")
+	if err == nil {
+		t.Fatal("Load() error = nil, want unknown keys")
+	}
+	want := []string{
+		"agent.restart_options.restart_delay (line 4): did you mean 'delay'?",
+		"agent.task_options.max_task_timout (line 6): did you mean 'max_task_timeout'?",
+		"loger (line 8): did you mean 'logger'?",
+	}
+	for _, line := range want {
+		if !strings.Contains(err.Error(), line) {
+			t.Errorf("Load() error = %v, want %q", err, line)
+		}
+	}
+	if strings.Index(err.Error(), want[0]) > strings.Index(err.Error(), want[2]) {
+		t.Errorf("Load() error = %v, want keys sorted by line", err)
+	}
+}
+
+func TestLoadRejectsUnknownProfileKeys(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `version: 2
+profiles:
+  dev:
+    logger:
+      levl: debug
+`)
+
+	_, err := Load(path, "")
+	if err == nil || !strings.Contains(err.Error(), "profiles.dev.logger.levl (line 5): did you mean 'level'?") {
+		t.Fatalf("Load() error = %v, want unknown profile key with suggestion", err)
+	}
+}
+
+func TestClosestKey(t *testing.T) {
+	fields := structKeys(reflect.TypeOf(agentRestartOptions{}))
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "max_restart", want: "max_restarts"},
+		{key: "restart_delay", want: "delay"},
+		{key: "completely_different", want: ""},
+	}
+	for _, tt := range tests {
+		if got := closestKey(tt.key, fields); got != tt.want {
+			t.Errorf("closestKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// writeConfig writes a config file into a temporary directory of the test and returns its path.
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/logger"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single invalid config value.
+type FieldError struct {
+	// Path is the dotted key path of the value, e.g. "agent.restart_options.delay".
+	Path string
+
+	// Message describes what is wrong with the value.
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError aggregates every problem found in the config.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		lines = append(lines, "  "+field.Error())
+	}
+	return fmt.Sprintf(ErrConfigValidate, fmt.Sprintf("%d problem(s):\n%s", len(e.Fields), strings.Join(lines, "\n")))
+}
+
+func (e *ValidationError) add(path, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks all config sections at once and returns a *ValidationError listing every problem.
+// Zero values are allowed where the consuming package applies a default.
+func (c *Config) Validate() error {
+	result := &ValidationError{}
+
+	if err := getValidator().Struct(c); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return initError("validation failed: %v", err)
+		}
+		for _, fieldError := range validationErrors {
+			path := fieldPath(fieldError)
+			result.add(path, "%s", fieldMessage(fieldError, c.displayValue(path, fieldError.Value())))
+		}
+	}
+	c.validateRules(result)
+
+	if len(result.Fields) == 0 {
+		return nil
+	}
+	return result
+}
+
+// validateRules checks constraints that involve several fields.
+func (c *Config) validateRules(result *ValidationError) {
+	if c.Logger.Output == logger.OutputFile {
+		switch path := c.Logger.FileOptions.FilePath; {
+		case path == "":
+			result.add("logger.options.path", "is required when output is 'file'")
+		case filepath.Ext(path) == "":
+			result.add("logger.options.path", "must include filename, not just directory, got: '%v'",
+				c.displayValue("logger.options.path", path))
+		}
+	}
+
+	restart := c.Agent.RestartOptions
+	if restart.Delay > 0 && restart.MaxDelay > 0 && restart.Delay > restart.MaxDelay {
+		result.add("agent.restart_options.delay", "%v exceeds max_delay %v", restart.Delay, restart.MaxDelay)
+	}
+
+	names := make(map[string]int, len(c.Notifier.Webhooks))
+	for i, webhook := range c.Notifier.Webhooks {
+		path := fmt.Sprintf("notifier.webhooks[%d]", i)
+		if first, exists := names[webhook.Name]; exists && webhook.Name != "" {
+			result.add(path+".name", "duplicates name of notifier.webhooks[%d]: '%v'",
+				first, c.displayValue(path+".name", webhook.Name))
+		} else {
+			names[webhook.Name] = i
+		}
+		if webhook.RetryDelay > 0 && webhook.MaxRetryDelay > 0 && webhook.RetryDelay > webhook.MaxRetryDelay {
+			result.add(path+".retry_delay", "%v exceeds max_retry_delay %v", webhook.RetryDelay, webhook.MaxRetryDelay)
+		}
+	}
+}
+
+// fieldPath converts the validator namespace "Config.agent.restart_options.delay" into the config key path.
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldError.Namespace(), ".")
+	return path
+}
+
+// fieldMessage describes the failed check, value is the value shown in the message.
+func fieldMessage(fieldError validator.FieldError, value any) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s, got: %v", fieldError.Param(), value)
+	case "max":
+		return fmt.Sprintf("must be at most %s, got: %v", fieldError.Param(), value)
+	case "http_url":
+		return fmt.Sprintf("must be a valid http(s) url, got: '%v'", value)
+	case "log_level":
+		return fmt.Sprintf("invalid log level '%v', allowed values: %v", value, logger.ValidLogLevels)
+	case "log_format":
+		return fmt.Sprintf("invalid log format '%v', allowed values: %v", value, logger.ValidLogFormats)
+	case "log_output":
+		return fmt.Sprintf("invalid log output '%v', allowed values: %v", value, logger.ValidLogOutputs)
+	case "webhook_name":
+		return fmt.Sprintf("may contain only letters, digits, '-' and '_', got: '%v'", value)
+	case "event_type":
+		return fmt.Sprintf("unknown event type '%v', allowed values: %v", value, events.ValidEventTypes)
+	default:
+		return fmt.Sprintf("failed '%s' check, got: %v", fieldError.Tag(), value)
+	}
+}
+
+// ValidateComponents runs the logger, runner, worker and notifier validators on the converted
+// section configs, so rules enforced only by a component are checked before the agent starts.
+func (c *Config) ValidateComponents() error {
+	loggerConfig := c.Logger.ToLoggerConfig()
+	runnerConfig := c.Agent.ToRunnerConfig()
+	workerConfig := c.Agent.ToWorkerConfig()
+	notifierConfig := c.Notifier.ToNotifierConfig()
+
+	err := errors.Join(
+		loggerConfig.Validate(),
+		runnerConfig.Validate(),
+		workerConfig.Validate(),
+		notifierConfig.Validate(),
+	)
+	if err != nil {
+		return errors.New(c.redact(err.Error()))
+	}
+	return nil
+}
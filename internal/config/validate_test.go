@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Logger.Level = "dbg"
+	cfg.Logger.Output = "file"
+	cfg.Agent.RestartOptions.Delay = 2 * time.Minute
+	cfg.Agent.RestartOptions.MaxRestarts = 101
+	cfg.Notifier.Webhooks = []notifierWebhook{
+		{Name: "ops", URL: "https://example.com/hook", Events: []string{"task.failed"}},
+		{Name: "ops", URL: "ftp://example.com", Events: []string{"task.lost"}},
+	}
+	cfg.setDefaults()
+
+	err := cfg.Validate()
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+	want := []FieldError{
+		{Path: "logger.level", Message: "invalid log level 'dbg'"},
+		{Path: "agent.restart_options.delay", Message: "must be at most 1m"},
+		{Path: "agent.restart_options.max_restarts", Message: "must be at most 100, got: 101"},
+		{Path: "notifier.webhooks[1].url", Message: "must be a valid http(s) url, got: 'ftp://example.com'"},
+		{Path: "notifier.webhooks[1].events[0]", Message: "unknown event type 'task.lost'"},
+		{Path: "logger.options.path", Message: "is required when output is 'file'"},
+		{Path: "notifier.webhooks[1].name", Message: "duplicates name of notifier.webhooks[0]: 'ops'"},
+	}
+	for _, field := range want {
+		if !hasFieldError(validationError.Fields, field) {
+			t.Errorf("Validate() fields = %v, want %s", validationError.Fields, field)
+		}
+	}
+	if len(validationError.Fields) != len(want) {
+		t.Errorf("Validate() reported %d problems, want %d: %v", len(validationError.Fields), len(want), err)
+	}
+	if !strings.HasPrefix(err.Error(), "invalid config: 7 problem(s):") {
+		t.Errorf("Validate() error = %v, want the number of problems", err)
+	}
+}
+
+func TestValidateAcceptsDefaults(t *testing.T) {
+	cfg := defaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+// hasFieldError reports whether fields contain an error at the path of want whose message contains the message of want.
+func hasFieldError(fields []FieldError, want FieldError) bool {
+	for _, field := range fields {
+		if field.Path == want.Path && strings.Contains(field.Message, want.Message) {
+			return true
+		}
+	}
+	return false
+}
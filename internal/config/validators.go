@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/logger"
+	"go-ex-vm-agent/internal/notifier"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate *validator.Validate
+	once     sync.Once
+)
+
+func getValidator() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New()
+
+		// Field paths in errors use config keys instead of Go field names
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+
+		_ = validate.RegisterValidation("log_level", func(fl validator.FieldLevel) bool {
+			return logger.LogLevel(fl.Field().String()).IsValid()
+		})
+
+		_ = validate.RegisterValidation("log_format", func(fl validator.FieldLevel) bool {
+			return logger.LogFormat(fl.Field().String()).IsValid()
+		})
+
+		_ = validate.RegisterValidation("log_output", func(fl validator.FieldLevel) bool {
+			return logger.LogOutput(fl.Field().String()).IsValid()
+		})
+
+		_ = validate.RegisterValidation("webhook_name", func(fl validator.FieldLevel) bool {
+			return notifier.IsValidWebhookName(fl.Field().String())
+		})
+
+		_ = validate.RegisterValidation("event_type", func(fl validator.FieldLevel) bool {
+			return events.EventType(fl.Field().String()).IsValid()
+		})
+	})
+	return validate
+}
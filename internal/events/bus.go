@@ -0,0 +1,149 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultBufferSize размер буфера подписки по умолчанию
+const defaultBufferSize = 64
+
+// Bus шина событий с неблокирующей доставкой: если буфер подписчика заполнен,
+// событие для него отбрасывается и учитывается в счетчике dropped
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]*Subscription
+	nextID      uint64
+
+	published atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[uint64]*Subscription),
+	}
+}
+
+// Subscribe регистрирует подписчика на события указанных типов (без типов - на все события)
+func (b *Bus) Subscribe(buffer int, types ...EventType) *Subscription {
+	if buffer <= 0 {
+		buffer = defaultBufferSize
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &Subscription{
+		id:  b.nextID,
+		bus: b,
+		ch:  make(chan Event, buffer),
+	}
+	if len(types) > 0 {
+		sub.types = make(map[EventType]struct{}, len(types))
+		for _, t := range types {
+			sub.types[t] = struct{}{}
+		}
+	}
+	b.subscribers[sub.id] = sub
+	return sub
+}
+
+// SubscribeFunc регистрирует обработчик, который вызывается для каждого события в отдельной горутине подписки
+func (b *Bus) SubscribeFunc(buffer int, handler func(Event), types ...EventType) *Subscription {
+	sub := b.Subscribe(buffer, types...)
+	go func() {
+		for event := range sub.ch {
+			handler(event)
+		}
+	}()
+	return sub
+}
+
+// Publish доставляет событие всем подписчикам без блокировки.
+// Методы nil шины ничего не делают, поэтому шину можно не подключать
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.published.Add(1)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if !sub.accepts(event.Type) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Stats возвращает счетчики шины
+func (b *Bus) Stats() Stats {
+	if b == nil {
+		return Stats{}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return Stats{
+		Published:   b.published.Load(),
+		Dropped:     b.dropped.Load(),
+		Subscribers: len(b.subscribers),
+	}
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.subscribers[sub.id]; !exists {
+		return
+	}
+	delete(b.subscribers, sub.id)
+	close(sub.ch)
+}
+
+// Subscription подписка на события шины
+type Subscription struct {
+	id      uint64
+	bus     *Bus
+	ch      chan Event
+	types   map[EventType]struct{}
+	dropped atomic.Uint64
+}
+
+// Events возвращает канал событий подписки, канал закрывается при Close
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped возвращает количество событий, отброшенных из-за заполненного буфера
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close отменяет подписку
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
+
+func (s *Subscription) accepts(t EventType) bool {
+	if s.types == nil {
+		return true
+	}
+	_, ok := s.types[t]
+	return ok
+}
@@ -0,0 +1,53 @@
+package events
+
+import "testing"
+
+func TestBusDropsEventsWhenBufferIsFull(t *testing.T) {
+	bus := NewBus()
+	slow := bus.Subscribe(2)
+	fast := bus.Subscribe(10)
+	defer slow.Close()
+	defer fast.Close()
+
+	for range 5 {
+		bus.Publish(Event{Type: EventTaskStarted})
+	}
+
+	if got := slow.Dropped(); got != 3 {
+		t.Errorf("slow subscriber dropped %d events, want 3", got)
+	}
+	if got := fast.Dropped(); got != 0 {
+		t.Errorf("fast subscriber dropped %d events, want 0", got)
+	}
+	stats := bus.Stats()
+	if stats.Published != 5 || stats.Dropped != 3 || stats.Subscribers != 2 {
+		t.Errorf("Stats() = %+v, want 5 published, 3 dropped, 2 subscribers", stats)
+	}
+	if got := len(fast.Events()); got != 5 {
+		t.Errorf("fast subscriber received %d events, want 5", got)
+	}
+}
+
+func TestBusFiltersEventTypes(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(1, EventTaskFailed)
+
+	bus.Publish(Event{Type: EventTaskStarted})
+	bus.Publish(Event{Type: EventTaskFailed, Task: "sync"})
+
+	if got := sub.Dropped(); got != 0 {
+		t.Fatalf("subscriber dropped %d events of other types, want 0", got)
+	}
+	event := <-sub.Events()
+	if event.Type != EventTaskFailed || event.Task != "sync" || event.Time.IsZero() {
+		t.Fatalf("received %+v, want task.failed of 'sync' with time", event)
+	}
+
+	sub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("events channel is open after Close")
+	}
+	if got := bus.Stats().Subscribers; got != 0 {
+		t.Fatalf("Stats().Subscribers = %d after Close, want 0", got)
+	}
+}
@@ -0,0 +1,63 @@
+package events
+
+import "time"
+
+// EventType тип события задачи или runner'а
+type EventType string
+
+const (
+	EventTaskStarted   EventType = "task.started"
+	EventTaskCompleted EventType = "task.completed"
+	EventTaskFailed    EventType = "task.failed"
+	EventTaskStopped   EventType = "task.stopped"
+	EventTaskTimedOut  EventType = "task.timed_out"
+
+	EventRunnerRestarting    EventType = "runner.restarting"
+	EventRunnerReloadApplied EventType = "runner.reload_applied"
+	EventRunnerDegraded      EventType = "runner.degraded"
+	EventRunnerRecovered     EventType = "runner.recovered"
+	EventRunnerUpgraded      EventType = "runner.upgraded"
+	EventRunnerUpgradeFailed EventType = "runner.upgrade_failed"
+)
+
+// ValidEventTypes список всех типов событий
+var ValidEventTypes = []EventType{
+	EventTaskStarted,
+	EventTaskCompleted,
+	EventTaskFailed,
+	EventTaskStopped,
+	EventTaskTimedOut,
+	EventRunnerRestarting,
+	EventRunnerReloadApplied,
+	EventRunnerDegraded,
+	EventRunnerRecovered,
+	EventRunnerUpgraded,
+	EventRunnerUpgradeFailed,
+}
+
+// IsValid проверяет, что тип события входит в ValidEventTypes
+func (t EventType) IsValid() bool {
+	for _, valid := range ValidEventTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Event событие задачи или runner'а
+type Event struct {
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	Task  string    `json:"task,omitempty"`
+	Error string    `json:"error,omitempty"`
+	// Attributes дополнительные сведения о событии
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Stats счетчики шины событий
+type Stats struct {
+	Published   uint64
+	Dropped     uint64
+	Subscribers int
+}
@@ -0,0 +1,34 @@
+package instance
+
+import "fmt"
+
+const (
+	// ErrInstanceLock represents an error message format for failures while acquiring or releasing the instance lock.
+	ErrInstanceLock = "instance lock error: %s"
+)
+
+func lockError(format string, args ...any) error {
+	return fmt.Errorf(ErrInstanceLock, fmt.Sprintf(format, args...))
+}
+
+// LockedError возвращается, если блокировка уже захвачена другим процессом
+type LockedError struct {
+	// Path путь к файлу блокировки
+	Path string
+	// PID процесс, записанный в файл блокировки (0, если файл пуст или поврежден)
+	PID int
+	// Alive признак того, что процесс PID еще существует
+	Alive bool
+}
+
+func (e *LockedError) Error() string {
+	switch {
+	case e.PID == 0:
+		return fmt.Sprintf(ErrInstanceLock, fmt.Sprintf("another instance holds lock '%s'", e.Path))
+	case e.Alive:
+		return fmt.Sprintf(ErrInstanceLock, fmt.Sprintf("another instance is already running with pid %d (lock '%s')", e.PID, e.Path))
+	default:
+		// flock снимается только после закрытия всех дескрипторов, его мог унаследовать дочерний процесс
+		return fmt.Sprintf(ErrInstanceLock, fmt.Sprintf("lock '%s' is held, but recorded pid %d is not running; a child process may still hold the lock descriptor", e.Path, e.PID))
+	}
+}
@@ -0,0 +1,88 @@
+package instance
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Lock блокировка единственного экземпляра агента. Файл блокировки одновременно служит pidfile'ом
+type Lock struct {
+	path string
+	file *os.File
+	// stalePID процесс из файла, оставленного завершившимся без очистки экземпляром
+	stalePID int
+	// shared блокировка унаследована от предыдущего процесса, который еще может ее использовать
+	shared bool
+}
+
+// Path возвращает путь к файлу блокировки
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// StalePID возвращает PID из устаревшего файла блокировки, который был перезаписан при захвате (0, если его не было)
+func (l *Lock) StalePID() int {
+	return l.stalePID
+}
+
+// File возвращает дескриптор файла блокировки для передачи новому процессу при обновлении
+func (l *Lock) File() *os.File {
+	return l.file
+}
+
+// Own делает текущий процесс единственным владельцем унаследованной блокировки,
+// после этого Release снимает блокировку и удаляет файл
+func (l *Lock) Own() {
+	l.shared = false
+}
+
+// Reclaim записывает в файл PID текущего процесса, например после неудачной передачи блокировки
+func (l *Lock) Reclaim() error {
+	if l.file == nil {
+		return lockError("lock '%s' is already released", l.path)
+	}
+	if err := writePID(l.file); err != nil {
+		return lockError("failed to write pid to '%s': %v", l.path, err)
+	}
+	return nil
+}
+
+// Close закрывает дескриптор, не снимая блокировку и не удаляя файл. Используется,
+// когда блокировка передана новому процессу, который продолжает ее удерживать
+func (l *Lock) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	if err != nil {
+		return lockError("failed to close '%s': %v", l.path, err)
+	}
+	return nil
+}
+
+// readPID читает PID из начала файла, 0 если файл пуст или поврежден
+func readPID(f *os.File) int {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(string(bytes.TrimSpace(data)))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
+
+// writePID перезаписывает содержимое файла текущим PID
+func writePID(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,63 @@
+//go:build !unix
+
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Acquire создает pidfile path эксклюзивно. На платформах без flock нельзя надежно определить,
+// жив ли владелец, поэтому существующий файл всегда считается захваченной блокировкой
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, lockError("failed to create lock dir for '%s': %v", path, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		pid := 0
+		if existing, err := os.Open(path); err == nil {
+			pid = readPID(existing)
+			existing.Close()
+		}
+		return nil, &LockedError{Path: path, PID: pid, Alive: true}
+	}
+	if err != nil {
+		return nil, lockError("failed to create lock file '%s': %v", path, err)
+	}
+
+	if err := writePID(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, lockError("failed to write pid to '%s': %v", path, err)
+	}
+	return &Lock{path: path, file: f}, nil
+}
+
+// Adopt принимает pidfile, унаследованный от предыдущего процесса при обновлении
+func Adopt(path string, f *os.File) (*Lock, error) {
+	if err := writePID(f); err != nil {
+		f.Close()
+		return nil, lockError("failed to write pid to '%s': %v", path, err)
+	}
+	return &Lock{path: path, file: f, shared: true}, nil
+}
+
+// Release удаляет pidfile
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	if l.shared {
+		return l.Close()
+	}
+	defer func() { l.file = nil }()
+
+	err := errors.Join(l.file.Close(), os.Remove(l.path))
+	if err != nil {
+		return lockError("failed to release '%s': %v", l.path, err)
+	}
+	return nil
+}
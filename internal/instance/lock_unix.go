@@ -0,0 +1,121 @@
+//go:build unix
+
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Acquire захватывает эксклюзивную flock блокировку файла path и записывает в него PID текущего процесса.
+// Блокировка снимается ядром при завершении процесса, поэтому файл с PID завершившегося процесса
+// считается устаревшим и перезаписывается
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, lockError("failed to create lock dir for '%s': %v", path, err)
+	}
+
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, lockError("failed to open lock file '%s': %v", path, err)
+		}
+
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			pid := readPID(f)
+			f.Close()
+			if errors.Is(err, syscall.EWOULDBLOCK) {
+				return nil, &LockedError{Path: path, PID: pid, Alive: processAlive(pid)}
+			}
+			return nil, lockError("failed to lock '%s': %v", path, err)
+		}
+
+		// Предыдущий владелец мог удалить файл между open и flock - тогда блокировка
+		// захвачена на inode, которого больше нет по пути path, и нужно повторить попытку
+		if !sameFile(f, path) {
+			f.Close()
+			continue
+		}
+
+		lock := &Lock{path: path, file: f}
+		if pid := readPID(f); pid != 0 && pid != os.Getpid() {
+			lock.stalePID = pid
+		}
+		if err := writePID(f); err != nil {
+			f.Close()
+			return nil, lockError("failed to write pid to '%s': %v", path, err)
+		}
+		return lock, nil
+	}
+}
+
+// Adopt принимает дескриптор блокировки, унаследованный от предыдущего процесса при обновлении.
+// Дескриптор ссылается на то же открытое описание файла, поэтому flock уже удерживается
+func Adopt(path string, f *os.File) (*Lock, error) {
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, lockError("inherited descriptor does not hold lock '%s': %v", path, err)
+	}
+	if !sameFile(f, path) {
+		f.Close()
+		return nil, lockError("inherited descriptor does not match lock file '%s'", path)
+	}
+	if err := writePID(f); err != nil {
+		f.Close()
+		return nil, lockError("failed to write pid to '%s': %v", path, err)
+	}
+	return &Lock{path: path, file: f, shared: true}, nil
+}
+
+// Release удаляет файл блокировки и снимает блокировку. Файл удаляется до снятия блокировки,
+// чтобы следующий экземпляр не захватил блокировку на удаленном inode
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	if l.shared {
+		// Снятие flock на общем описании файла сняло бы блокировку и у предыдущего процесса
+		return l.Close()
+	}
+	defer func() { l.file = nil }()
+
+	var errs []error
+	if sameFile(l.file, l.path) {
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		errs = append(errs, err)
+	}
+	if err := l.file.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return lockError("failed to release '%s': %v", l.path, err)
+	}
+	return nil
+}
+
+func sameFile(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
+
+// processAlive проверяет существование процесса сигналом 0
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,123 @@
+//go:build unix
+
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestAcquireRejectsSecondInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.pid")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if got := readPIDFile(t, path); got != os.Getpid() {
+		t.Fatalf("pid file holds %d, want %d", got, os.Getpid())
+	}
+
+	_, err = Acquire(path)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != os.Getpid() || !locked.Alive {
+		t.Fatalf("second Acquire() error = %v, want LockedError with the live pid", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("pid file exists after Release: %v", err)
+	}
+	again, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() after Release error = %v", err)
+	}
+	_ = again.Release()
+}
+
+func TestAcquireTakesOverStalePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.pid")
+	if err := os.WriteFile(path, []byte("4194304\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer lock.Release()
+
+	if got := lock.StalePID(); got != 4194304 {
+		t.Fatalf("StalePID() = %d, want 4194304", got)
+	}
+	if got := readPIDFile(t, path); got != os.Getpid() {
+		t.Fatalf("pid file holds %d, want %d", got, os.Getpid())
+	}
+}
+
+func TestAdoptedLockIsReleasedOnlyAfterOwn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.pid")
+	parent, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	fd, err := syscall.Dup(int(parent.File().Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := Adopt(path, os.NewFile(uintptr(fd), path))
+	if err != nil {
+		t.Fatalf("Adopt() error = %v", err)
+	}
+
+	// Передача не подтверждена: блокировка и файл остаются у предыдущего процесса
+	if err := child.Release(); err != nil {
+		t.Fatalf("Release() of shared lock error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("pid file removed by shared lock: %v", err)
+	}
+	if _, err := Acquire(path); err == nil {
+		t.Fatal("lock released by shared lock")
+	}
+
+	fd, err = syscall.Dup(int(parent.File().Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err = Adopt(path, os.NewFile(uintptr(fd), path))
+	if err != nil {
+		t.Fatalf("Adopt() error = %v", err)
+	}
+	if err := parent.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	child.Own()
+	if err := child.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("pid file exists after owned Release: %v", err)
+	}
+}
+
+func readPIDFile(t *testing.T, path string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read pid file: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("pid file holds %q: %v", data, err)
+	}
+	return pid
+}
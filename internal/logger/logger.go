@@ -21,20 +21,19 @@ type Logger struct {
 
 func New(cfg Config) (*Logger, error) {
 	if err := cfg.Validate(); err != nil {
-		return nil, initError(err.Error())
+		return nil, initError("%s", err.Error())
 	}
 
 	level, err := parseLogLevel(cfg.Level)
 	if err != nil {
-		return nil, initError(err.Error())
+		return nil, initError("%s", err.Error())
 	}
 	writer, err := setupWriter(cfg)
 	if err != nil {
-		return nil, initError(err.Error())
+		return nil, initError("%s", err.Error())
 	}
 
 	var logger zerolog.Logger
-	logger = logger.Level(level)
 	switch cfg.Format {
 	case FormatConsole:
 		logger = zerolog.New(zerolog.ConsoleWriter{
@@ -49,9 +48,21 @@ func New(cfg Config) (*Logger, error) {
 	if cfg.Output != OutputJournal {
 		logger = logger.With().Timestamp().Logger()
 	}
+	// The level is global, so SetLevel can change it later without replacing the logger
+	zerolog.SetGlobalLevel(level)
 	return &Logger{Logger: &logger}, nil
 }
 
+// SetLevel changes the level of the loggers created by New at runtime, e.g. when the config is reloaded.
+func SetLevel(level LogLevel) error {
+	parsed, err := parseLogLevel(level)
+	if err != nil {
+		return validateError("%s", err.Error())
+	}
+	zerolog.SetGlobalLevel(parsed)
+	return nil
+}
+
 func parseLogLevel(level LogLevel) (zerolog.Level, error) {
 	switch level {
 	case LevelDebug:
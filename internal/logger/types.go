@@ -18,9 +18,9 @@ func (l LogLevel) IsValid() bool {
 // LogFormat represents the format of log output.
 type LogFormat string
 
-// IsValid checks if the LogFormat is one of the predefined valid log formats in the validLogFormats list.
+// IsValid checks if the LogFormat is one of the predefined valid log formats in the ValidLogFormats list.
 func (f LogFormat) IsValid() bool {
-	for _, valid := range validLogFormats {
+	for _, valid := range ValidLogFormats {
 		if f == valid {
 			return true
 		}
@@ -31,9 +31,9 @@ func (f LogFormat) IsValid() bool {
 // LogOutput represents the destination where log messages are written.
 type LogOutput string
 
-// IsValid checks if the LogOutput is one of the predefined valid log outputs in the validLogOutputs list.
+// IsValid checks if the LogOutput is one of the predefined valid log outputs in the ValidLogOutputs list.
 func (o LogOutput) IsValid() bool {
-	for _, valid := range validLogOutputs {
+	for _, valid := range ValidLogOutputs {
 		if o == valid {
 			return true
 		}
@@ -97,12 +97,14 @@ var ValidLogLevels = []LogLevel{
 	LevelPanic,
 }
 
-var validLogFormats = []LogFormat{
+// ValidLogFormats defines a list of supported log output formats.
+var ValidLogFormats = []LogFormat{
 	FormatConsole,
 	FormatJSON,
 }
 
-var validLogOutputs = []LogOutput{
+// ValidLogOutputs defines a list of supported log output destinations.
+var ValidLogOutputs = []LogOutput{
 	OutputStdout,
 	OutputStderr,
 	OutputFile,
@@ -117,16 +119,16 @@ func logLevelsString() string {
 }
 
 func logFormatsString() string {
-	formats := make([]string, len(validLogFormats))
-	for i, format := range validLogFormats {
+	formats := make([]string, len(ValidLogFormats))
+	for i, format := range ValidLogFormats {
 		formats[i] = string(format)
 	}
 	return strings.Join(formats, " ")
 }
 
 func logOutputsString() string {
-	outputs := make([]string, len(validLogOutputs))
-	for i, output := range validLogOutputs {
+	outputs := make([]string, len(ValidLogOutputs))
+	for i, output := range ValidLogOutputs {
 		outputs[i] = string(output)
 	}
 	return strings.Join(outputs, " ")
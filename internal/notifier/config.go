@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"errors"
+	"time"
+
+	"go-ex-vm-agent/internal/events"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NoRetries значение WebhookConfig.MaxRetries, отключающее повторы отправки
+const NoRetries = -1
+
+func defaultConfig() Config {
+	return Config{
+		QueueDir:  "",
+		QueueSize: 1000,
+	}
+}
+
+func defaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		Timeout:       10 * time.Second,
+		MaxRetries:    3,
+		RetryDelay:    time.Second,
+		MaxRetryDelay: time.Minute,
+	}
+}
+
+type Config struct {
+	// Webhooks список HTTP endpoint'ов, получающих события
+	Webhooks []WebhookConfig `mapstructure:"webhooks" validate:"dive"`
+	// QueueDir каталог очереди неотправленных событий (пусто = очередь только в памяти)
+	QueueDir string `mapstructure:"queue_dir"`
+	// QueueSize максимальное количество неотправленных событий для одного webhook'а,
+	// при переполнении отбрасываются самые старые
+	QueueSize int `mapstructure:"queue_size" validate:"min=1,max=100000"`
+}
+
+type WebhookConfig struct {
+	// Name имя webhook'а, используется в логах и как имя подкаталога очереди
+	Name string `mapstructure:"name" validate:"required,webhook_name"`
+	// URL адрес, на который отправляются события методом POST
+	URL string `mapstructure:"url" validate:"required,http_url"`
+	// Events типы событий, которые отправляются на webhook (пусто = все события)
+	Events []events.EventType `mapstructure:"events"`
+	// Secret ключ HMAC-SHA256 подписи тела запроса (пусто = без подписи)
+	Secret string `mapstructure:"secret"`
+	// Headers дополнительные заголовки запроса
+	Headers map[string]string `mapstructure:"headers"`
+	// Timeout таймаут одного HTTP запроса
+	Timeout time.Duration `mapstructure:"timeout" validate:"min=1s,max=5m"`
+	// MaxRetries количество повторов неудачной отправки (0 = по умолчанию, NoRetries = без повторов)
+	MaxRetries int `mapstructure:"max_retries" validate:"min=-1,max=20"`
+	// RetryDelay задержка перед первым повтором, далее удваивается
+	RetryDelay time.Duration `mapstructure:"retry_delay" validate:"min=100ms,max=1m"`
+	// MaxRetryDelay максимальная задержка между повторами
+	MaxRetryDelay time.Duration `mapstructure:"max_retry_delay" validate:"min=1s,max=1h"`
+}
+
+func (c *Config) Validate() error {
+	c.setDefaults()
+
+	if err := getValidator().Struct(c); err != nil {
+		return c.formatValidationErr(err)
+	}
+	return c.validateRules()
+}
+
+func (c *Config) setDefaults() {
+	defaults := defaultConfig()
+
+	if c.QueueSize == 0 {
+		c.QueueSize = defaults.QueueSize
+	}
+	for i := range c.Webhooks {
+		c.Webhooks[i].setDefaults()
+	}
+}
+
+func (c *WebhookConfig) setDefaults() {
+	defaults := defaultWebhookConfig()
+
+	if c.Timeout == 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaults.MaxRetries
+	}
+	if c.RetryDelay == 0 {
+		c.RetryDelay = defaults.RetryDelay
+	}
+	if c.MaxRetryDelay == 0 {
+		c.MaxRetryDelay = defaults.MaxRetryDelay
+	}
+}
+
+// retries возвращает количество повторов отправки с учетом NoRetries
+func (c WebhookConfig) retries() int {
+	return max(c.MaxRetries, 0)
+}
+
+func (c *Config) formatValidationErr(err error) error {
+	var validationErrors validator.ValidationErrors
+
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			switch fieldError.Field() {
+			case "QueueSize":
+				return initError("queue size must be between 1 and 100000, got: %d", c.QueueSize)
+			case "Name":
+				return initError("webhook name is required and may contain only letters, digits, '-' and '_', got: '%v'", fieldError.Value())
+			case "URL":
+				return initError("webhook url must be a valid http(s) url, got: '%v'", fieldError.Value())
+			case "Timeout":
+				return initError("webhook timeout must be between 1s and 5m, got: %v", fieldError.Value())
+			case "MaxRetries":
+				return initError("webhook max retries must be between 0 and 20 or -1 to disable retries, got: %v", fieldError.Value())
+			case "RetryDelay":
+				return initError("webhook retry delay must be between 100ms and 1m, got: %v", fieldError.Value())
+			case "MaxRetryDelay":
+				return initError("webhook max retry delay must be between 1s and 1h, got: %v", fieldError.Value())
+			default:
+				return initError("validation failed for field '%s': %s", fieldError.Namespace(), fieldError.Tag())
+			}
+		}
+	}
+	return initError("validation failed: %v", err)
+}
+
+func (c *Config) validateRules() error {
+	names := make(map[string]struct{}, len(c.Webhooks))
+	for _, webhook := range c.Webhooks {
+		if _, exists := names[webhook.Name]; exists {
+			return initError("duplicate webhook name '%s'", webhook.Name)
+		}
+		names[webhook.Name] = struct{}{}
+
+		if webhook.RetryDelay > webhook.MaxRetryDelay {
+			return initError("webhook '%s' retry delay %v exceeds max retry delay %v", webhook.Name, webhook.RetryDelay, webhook.MaxRetryDelay)
+		}
+	}
+	return nil
+}
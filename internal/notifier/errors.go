@@ -0,0 +1,21 @@
+package notifier
+
+import "fmt"
+
+const (
+	ErrNotifierInit = "failed to initialize notifier: %s"
+	ErrQueue        = "notifier queue error: %s"
+	ErrDelivery     = "webhook delivery error: %s"
+)
+
+func initError(format string, args ...any) error {
+	return fmt.Errorf(ErrNotifierInit, fmt.Sprintf(format, args...))
+}
+
+func queueError(format string, args ...any) error {
+	return fmt.Errorf(ErrQueue, fmt.Sprintf(format, args...))
+}
+
+func deliveryError(format string, args ...any) error {
+	return fmt.Errorf(ErrDelivery, fmt.Sprintf(format, args...))
+}
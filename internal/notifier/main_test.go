@@ -0,0 +1,17 @@
+package notifier
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	agent "go-ex-vm-agent"
+	"go-ex-vm-agent/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	nop := zerolog.Nop()
+	agent.Logger = &logger.Logger{Logger: &nop}
+	os.Exit(m.Run())
+}
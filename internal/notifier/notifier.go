@@ -0,0 +1,410 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	agent "go-ex-vm-agent"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-ex-vm-agent/internal/events"
+)
+
+const (
+	// HeaderEvent заголовок с типом события
+	HeaderEvent = "X-VM-Agent-Event"
+	// HeaderDelivery заголовок с уникальным идентификатором доставки
+	HeaderDelivery = "X-VM-Agent-Delivery"
+	// HeaderSignature заголовок с HMAC-SHA256 подписью тела запроса в формате "sha256=<hex>"
+	HeaderSignature = "X-VM-Agent-Signature"
+
+	// subscriptionBuffer размер буфера подписки на шину событий
+	subscriptionBuffer = 256
+)
+
+// Payload тело запроса, отправляемого на webhook
+type Payload struct {
+	ID       string       `json:"id"`
+	Hostname string       `json:"hostname"`
+	Event    events.Event `json:"event"`
+}
+
+// Stats счетчики доставки webhook'а
+type Stats struct {
+	Queued    int
+	Delivered uint64
+	Failed    uint64
+	Dropped   uint64
+}
+
+// Notifier отправляет события шины на настроенные webhook'и
+type Notifier struct {
+	config   Config
+	bus      *events.Bus
+	hostname string
+	senders  []*sender
+
+	mu     sync.Mutex
+	sub    *events.Subscription
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(config Config, bus *events.Bus) (*Notifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, initError("%s", err.Error())
+	}
+	if bus == nil {
+		return nil, initError("event bus cannot be nil")
+	}
+
+	hostname, _ := os.Hostname()
+	n := &Notifier{
+		config:   config,
+		bus:      bus,
+		hostname: hostname,
+	}
+
+	for _, webhook := range config.Webhooks {
+		var q queue = newMemoryQueue(config.QueueSize)
+		if config.QueueDir != "" {
+			dq, err := newDiskQueue(filepath.Join(config.QueueDir, webhook.Name), config.QueueSize)
+			if err != nil {
+				return nil, initError("webhook '%s': %v", webhook.Name, err)
+			}
+			q = dq
+		}
+		n.senders = append(n.senders, newSender(webhook, q))
+	}
+	return n, nil
+}
+
+// Start подписывается на шину событий и запускает отправку
+func (n *Notifier) Start(ctx context.Context) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.sub != nil || len(n.senders) == 0 {
+		return
+	}
+
+	ctx, n.cancel = context.WithCancel(ctx)
+	n.sub = n.bus.Subscribe(subscriptionBuffer)
+
+	for _, s := range n.senders {
+		n.wg.Add(1)
+		go func(s *sender) {
+			defer n.wg.Done()
+			s.run(ctx)
+		}(s)
+	}
+
+	n.wg.Add(1)
+	go func(sub *events.Subscription) {
+		defer n.wg.Done()
+		for event := range sub.Events() {
+			n.dispatch(event)
+		}
+	}(n.sub)
+
+	agent.Logger.Info().
+		Int("webhooks", len(n.senders)).
+		Msg("Notifier started")
+}
+
+// Stop отписывается от шины и останавливает отправку. Неотправленные события
+// остаются в очереди на диске, если она настроена
+func (n *Notifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.sub == nil {
+		return
+	}
+	n.sub.Close()
+	n.cancel()
+	n.wg.Wait()
+	n.sub = nil
+
+	agent.Logger.Info().Msg("Notifier stopped")
+}
+
+// Stats возвращает счетчики доставки по имени webhook'а
+func (n *Notifier) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(n.senders))
+	for _, s := range n.senders {
+		stats[s.config.Name] = Stats{
+			Queued:    s.queue.len(),
+			Delivered: s.delivered.Load(),
+			Failed:    s.failed.Load(),
+			Dropped:   s.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// dispatch ставит событие в очереди webhook'ов, фильтры которых его принимают
+func (n *Notifier) dispatch(event events.Event) {
+	payload := Payload{
+		ID:       newDeliveryID(),
+		Hostname: n.hostname,
+		Event:    event,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		agent.Logger.Error().
+			Err(err).
+			Str("event", string(event.Type)).
+			Msg("Failed to encode webhook payload")
+		return
+	}
+
+	for _, s := range n.senders {
+		if s.accepts(event.Type) {
+			s.enqueue(body)
+		}
+	}
+}
+
+// sender доставляет payload'ы одного webhook'а по очереди, сохраняя порядок событий
+type sender struct {
+	config WebhookConfig
+	queue  queue
+	client *http.Client
+	filter map[events.EventType]struct{}
+	wake   chan struct{}
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func newSender(config WebhookConfig, q queue) *sender {
+	s := &sender{
+		config: config,
+		queue:  q,
+		client: &http.Client{Timeout: config.Timeout},
+		wake:   make(chan struct{}, 1),
+	}
+	if len(config.Events) > 0 {
+		s.filter = make(map[events.EventType]struct{}, len(config.Events))
+		for _, t := range config.Events {
+			s.filter[t] = struct{}{}
+		}
+	}
+	return s
+}
+
+func (s *sender) accepts(t events.EventType) bool {
+	if s.filter == nil {
+		return true
+	}
+	_, ok := s.filter[t]
+	return ok
+}
+
+func (s *sender) enqueue(body []byte) {
+	dropped, err := s.queue.push(body)
+	if err != nil {
+		s.dropped.Add(1)
+		agent.Logger.Error().
+			Err(err).
+			Str("webhook", s.config.Name).
+			Msg("Failed to enqueue webhook event")
+		return
+	}
+	if dropped > 0 {
+		s.dropped.Add(uint64(dropped))
+		agent.Logger.Warn().
+			Str("webhook", s.config.Name).
+			Int("dropped", dropped).
+			Msg("Webhook queue is full, oldest events dropped")
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run отправляет элементы очереди, пока не отменен контекст
+func (s *sender) run(ctx context.Context) {
+	for {
+		item, ok, err := s.queue.peek()
+		if err != nil {
+			agent.Logger.Error().
+				Err(err).
+				Str("webhook", s.config.Name).
+				Msg("Failed to read webhook queue")
+		}
+		if err != nil || !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+			}
+			continue
+		}
+
+		err = s.deliver(ctx, item.payload)
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err == nil:
+			s.delivered.Add(1)
+		case isPermanent(err):
+			s.failed.Add(1)
+			agent.Logger.Error().
+				Err(err).
+				Str("webhook", s.config.Name).
+				Msg("Webhook rejected event, dropping it")
+		default:
+			// Endpoint недоступен - событие остается в очереди до следующей попытки
+			s.failed.Add(1)
+			agent.Logger.Warn().
+				Err(err).
+				Str("webhook", s.config.Name).
+				Int("queued", s.queue.len()).
+				Dur("retry_in", s.config.MaxRetryDelay).
+				Msg("Webhook is unavailable, events kept in queue")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.config.MaxRetryDelay):
+			}
+			continue
+		}
+
+		if err := s.queue.remove(item); err != nil {
+			agent.Logger.Error().
+				Err(err).
+				Str("webhook", s.config.Name).
+				Msg("Failed to remove delivered event from queue")
+		}
+	}
+}
+
+// deliver отправляет payload с повторами и экспоненциальной задержкой
+func (s *sender) deliver(ctx context.Context, body []byte) error {
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return &permanentError{deliveryError("invalid queued payload: %v", err)}
+	}
+	delay := s.config.RetryDelay
+
+	var err error
+	for attempt := 0; attempt <= s.config.retries(); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, s.config.MaxRetryDelay)
+		}
+
+		err = s.post(ctx, payload, body)
+		if err == nil || isPermanent(err) {
+			return err
+		}
+		agent.Logger.Debug().
+			Err(err).
+			Str("webhook", s.config.Name).
+			Int("attempt", attempt+1).
+			Msg("Webhook delivery attempt failed")
+	}
+	return err
+}
+
+func (s *sender) post(ctx context.Context, payload Payload, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{deliveryError("failed to create request: %v", redactURL(err))}
+	}
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(payload.Event.Type))
+	req.Header.Set(HeaderDelivery, payload.ID)
+	if s.config.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign([]byte(s.config.Secret), body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return deliveryError("request to webhook '%s' failed: %v", s.config.Name, redactURL(err))
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return &permanentError{deliveryError("webhook '%s' responded with status %d", s.config.Name, resp.StatusCode)}
+	default:
+		return deliveryError("webhook '%s' responded with status %d", s.config.Name, resp.StatusCode)
+	}
+}
+
+// redactURL убирает из ошибки url webhook'а все, кроме схемы и хоста: путь, query и userinfo
+// часто содержат токены, которые не должны попадать в логи
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := "<redacted>"
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil && u.Host != "" {
+		redacted = u.Scheme + "://" + u.Host + "/<redacted>"
+	}
+	return &url.Error{Op: urlErr.Op, URL: redacted, Err: urlErr.Err}
+}
+
+// Sign возвращает hex HMAC-SHA256 подпись тела запроса
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// permanentError ошибка, при которой повторная отправка бессмысленна
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
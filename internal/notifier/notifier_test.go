@@ -0,0 +1,157 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-ex-vm-agent/internal/events"
+)
+
+func TestSign(t *testing.T) {
+	// RFC 4231, test case 2
+	got := Sign([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestDeliverRetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != "sha256="+Sign([]byte("secret"), body) {
+			t.Errorf("request %d has invalid signature %q", requests.Load()+1, r.Header.Get(HeaderSignature))
+		}
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := testSender(server.URL, newMemoryQueue(10))
+	s.config.Secret = "secret"
+	start := time.Now()
+	if err := s.deliver(context.Background(), testPayload(t)); err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("webhook received %d requests, want 3", got)
+	}
+	// Задержки 10ms и 20ms: вторая удваивается
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("retries took %v, want at least 30ms of backoff", elapsed)
+	}
+}
+
+func TestDeliverWithoutRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	s := testSender(server.URL, newMemoryQueue(10))
+	s.config.MaxRetries = NoRetries
+	if err := s.deliver(context.Background(), testPayload(t)); err == nil {
+		t.Fatal("deliver() error = nil, want server error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("webhook received %d requests, want 1", got)
+	}
+}
+
+func TestWebhookMaxRetriesDefaults(t *testing.T) {
+	tests := []struct {
+		maxRetries int
+		want       int
+	}{
+		{maxRetries: 0, want: 3},
+		{maxRetries: 5, want: 5},
+		{maxRetries: NoRetries, want: NoRetries},
+	}
+	for _, tt := range tests {
+		cfg := Config{Webhooks: []WebhookConfig{{Name: "test", URL: "https://example.com", MaxRetries: tt.maxRetries}}}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate() with max retries %d error = %v", tt.maxRetries, err)
+		}
+		if got := cfg.Webhooks[0].MaxRetries; got != tt.want {
+			t.Errorf("max retries %d after Validate() = %d, want %d", tt.maxRetries, got, tt.want)
+		}
+	}
+
+	cfg := Config{Webhooks: []WebhookConfig{{Name: "test", URL: "https://example.com", MaxRetries: -2}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() with max retries -2 error = nil")
+	}
+}
+
+func TestSenderDropsRejectedEvents(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	q := newMemoryQueue(10)
+	s := testSender(server.URL, q)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	s.enqueue(testPayload(t))
+	deadline := time.Now().Add(time.Second)
+	for s.failed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := s.failed.Load(); got != 1 {
+		t.Fatalf("failed = %d, want 1", got)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("webhook received %d requests, want 1 without retries", got)
+	}
+	if got := q.len(); got != 0 {
+		t.Fatalf("queue holds %d events, want the rejected event dropped", got)
+	}
+}
+
+func testSender(url string, q queue) *sender {
+	return newSender(WebhookConfig{
+		Name:          "test",
+		URL:           url,
+		Timeout:       time.Second,
+		MaxRetries:    3,
+		RetryDelay:    10 * time.Millisecond,
+		MaxRetryDelay: time.Second,
+	}, q)
+}
+
+func testPayload(t *testing.T) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(Payload{
+		ID:    newDeliveryID(),
+		Event: events.Event{Type: events.EventTaskFailed, Task: "sync"},
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return body
+}
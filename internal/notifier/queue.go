@@ -0,0 +1,218 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queue ограниченная FIFO очередь неотправленных payload'ов.
+// При переполнении отбрасывается самый старый элемент
+type queue interface {
+	// push добавляет payload в конец очереди и возвращает количество отброшенных элементов
+	push(payload []byte) (dropped int, err error)
+	// peek возвращает первый элемент очереди
+	peek() (item queueItem, ok bool, err error)
+	// remove удаляет элемент из очереди
+	remove(item queueItem) error
+	len() int
+}
+
+type queueItem struct {
+	id      string
+	payload []byte
+}
+
+// memoryQueue очередь в памяти, используется когда QueueDir не задан
+type memoryQueue struct {
+	mu    sync.Mutex
+	size  int
+	seq   uint64
+	items []queueItem
+}
+
+func newMemoryQueue(size int) *memoryQueue {
+	return &memoryQueue{size: size}
+}
+
+func (q *memoryQueue) push(payload []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	q.items = append(q.items, queueItem{id: fmt.Sprint(q.seq), payload: payload})
+
+	dropped := 0
+	for len(q.items) > q.size {
+		q.items = q.items[1:]
+		dropped++
+	}
+	return dropped, nil
+}
+
+func (q *memoryQueue) peek() (queueItem, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return queueItem{}, false, nil
+	}
+	return q.items[0], true, nil
+}
+
+func (q *memoryQueue) remove(item queueItem) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, it := range q.items {
+		if it.id == item.id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *memoryQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// diskQueue очередь на диске: каждый payload хранится в отдельном файле,
+// имена файлов упорядочены по времени добавления, поэтому очередь переживает перезапуск агента
+type diskQueue struct {
+	mu   sync.Mutex
+	dir  string
+	size int
+	seq  uint64
+	ids  []string
+}
+
+const (
+	queueFileExt = ".json"
+	// queueTmpExt расширение файла, который записывается перед переименованием в элемент очереди
+	queueTmpExt = ".tmp"
+)
+
+func newDiskQueue(dir string, size int) (*diskQueue, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, queueError("failed to create queue dir '%s': %v", dir, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, queueError("failed to read queue dir '%s': %v", dir, err)
+	}
+
+	q := &diskQueue{dir: dir, size: size}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, queueTmpExt) {
+			// Файл не дописан до падения агента, в очередь он не попал
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, queueError("failed to remove unfinished queue item '%s': %v", name, err)
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, queueFileExt) {
+			continue
+		}
+		q.ids = append(q.ids, strings.TrimSuffix(name, queueFileExt))
+	}
+	sort.Strings(q.ids)
+
+	// Размер очереди мог уменьшиться с прошлого запуска
+	for len(q.ids) > q.size {
+		if err := q.removeFile(q.ids[0]); err != nil {
+			return nil, err
+		}
+		q.ids = q.ids[1:]
+	}
+	return q, nil
+}
+
+func (q *diskQueue) push(payload []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	id := fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), q.seq%1000000)
+
+	tmp := filepath.Join(q.dir, id+queueTmpExt)
+	if err := os.WriteFile(tmp, payload, 0o600); err != nil {
+		return 0, queueError("failed to write queue item: %v", err)
+	}
+	if err := os.Rename(tmp, q.path(id)); err != nil {
+		_ = os.Remove(tmp)
+		return 0, queueError("failed to write queue item: %v", err)
+	}
+	q.ids = append(q.ids, id)
+
+	dropped := 0
+	for len(q.ids) > q.size {
+		if err := q.removeFile(q.ids[0]); err != nil {
+			return dropped, err
+		}
+		q.ids = q.ids[1:]
+		dropped++
+	}
+	return dropped, nil
+}
+
+func (q *diskQueue) peek() (queueItem, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.ids) > 0 {
+		id := q.ids[0]
+		payload, err := os.ReadFile(q.path(id))
+		if errors.Is(err, os.ErrNotExist) {
+			q.ids = q.ids[1:]
+			continue
+		}
+		if err != nil {
+			return queueItem{}, false, queueError("failed to read queue item '%s': %v", id, err)
+		}
+		return queueItem{id: id, payload: payload}, true, nil
+	}
+	return queueItem{}, false, nil
+}
+
+func (q *diskQueue) remove(item queueItem) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, id := range q.ids {
+		if id == item.id {
+			q.ids = append(q.ids[:i], q.ids[i+1:]...)
+			return q.removeFile(id)
+		}
+	}
+	return nil
+}
+
+func (q *diskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ids)
+}
+
+func (q *diskQueue) path(id string) string {
+	return filepath.Join(q.dir, id+queueFileExt)
+}
+
+func (q *diskQueue) removeFile(id string) error {
+	if err := os.Remove(q.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return queueError("failed to remove queue item '%s': %v", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskQueueSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	q, err := newDiskQueue(dir, 10)
+	if err != nil {
+		t.Fatalf("newDiskQueue() error = %v", err)
+	}
+	for i := range 3 {
+		if _, err := q.push([]byte(fmt.Sprintf("event-%d", i))); err != nil {
+			t.Fatalf("push() error = %v", err)
+		}
+	}
+	item, _, _ := q.peek()
+	if err := q.remove(item); err != nil {
+		t.Fatalf("remove() error = %v", err)
+	}
+
+	restored, err := newDiskQueue(dir, 10)
+	if err != nil {
+		t.Fatalf("newDiskQueue() after restart error = %v", err)
+	}
+	if got := restored.len(); got != 2 {
+		t.Fatalf("restored queue holds %d events, want 2", got)
+	}
+	item, ok, err := restored.peek()
+	if err != nil || !ok || string(item.payload) != "event-1" {
+		t.Fatalf("peek() = %q, %v, %v, want event-1", item.payload, ok, err)
+	}
+}
+
+func TestQueueDropsOldestWhenFull(t *testing.T) {
+	disk, err := newDiskQueue(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("newDiskQueue() error = %v", err)
+	}
+	queues := map[string]queue{"memory": newMemoryQueue(2), "disk": disk}
+
+	for name, q := range queues {
+		t.Run(name, func(t *testing.T) {
+			dropped := 0
+			for i := range 3 {
+				n, err := q.push([]byte(fmt.Sprintf("event-%d", i)))
+				if err != nil {
+					t.Fatalf("push() error = %v", err)
+				}
+				dropped += n
+			}
+
+			if dropped != 1 || q.len() != 2 {
+				t.Fatalf("dropped %d, queue holds %d, want 1 dropped and 2 queued", dropped, q.len())
+			}
+			item, _, _ := q.peek()
+			if string(item.payload) != "event-1" {
+				t.Fatalf("oldest queued event is %q, want event-1", item.payload)
+			}
+		})
+	}
+}
+
+func TestDiskQueueShrinksToSizeOnRestart(t *testing.T) {
+	dir := t.TempDir()
+	q, err := newDiskQueue(dir, 5)
+	if err != nil {
+		t.Fatalf("newDiskQueue() error = %v", err)
+	}
+	for i := range 5 {
+		if _, err := q.push([]byte(fmt.Sprintf("event-%d", i))); err != nil {
+			t.Fatalf("push() error = %v", err)
+		}
+	}
+
+	restored, err := newDiskQueue(dir, 2)
+	if err != nil {
+		t.Fatalf("newDiskQueue() error = %v", err)
+	}
+	item, _, _ := restored.peek()
+	if restored.len() != 2 || string(item.payload) != "event-3" {
+		t.Fatalf("restored %d events starting with %q, want 2 starting with event-3", restored.len(), item.payload)
+	}
+}
+
+func TestDiskQueueRemovesUnfinishedItems(t *testing.T) {
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, "00000000000000000001-000001"+queueTmpExt)
+	if err := os.WriteFile(tmp, []byte("event"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := newDiskQueue(dir, 10)
+	if err != nil {
+		t.Fatalf("newDiskQueue() error = %v", err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("unfinished item is kept: %v", err)
+	}
+	if got := q.len(); got != 0 {
+		t.Fatalf("queue holds %d events, want 0", got)
+	}
+}
@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// WebhookNamePattern регулярное выражение допустимого имени webhook'а
+const WebhookNamePattern = `^[A-Za-z0-9_-]+$`
+
+var (
+	validate *validator.Validate
+	once     sync.Once
+
+	webhookNameRegexp = regexp.MustCompile(WebhookNamePattern)
+)
+
+// IsValidWebhookName проверяет, что имя webhook'а содержит только буквы, цифры, '-' и '_'
+func IsValidWebhookName(name string) bool {
+	return webhookNameRegexp.MatchString(name)
+}
+
+func getValidator() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New()
+
+		_ = validate.RegisterValidation("webhook_name", func(fl validator.FieldLevel) bool {
+			return IsValidWebhookName(fl.Field().String())
+		})
+	})
+	return validate
+}
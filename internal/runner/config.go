@@ -2,23 +2,25 @@ package runner
 
 import (
 	"errors"
+	"math/rand/v2"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
-const (
-	maxRestartDelay = 10 * time.Minute
-)
-
 // defaultConfig returns a Config object with predefined default values for all configurable parameters.
 func defaultConfig() Config {
 	return Config{
-		ShutdownTimeout:    60 * time.Second,
-		RestartDelay:       10 * time.Second,
-		MaxRestarts:        0,
-		EnableRestart:      false,
-		ExponentialBackoff: false,
+		ShutdownTimeout:       60 * time.Second,
+		RestartDelay:          10 * time.Second,
+		MaxRestarts:           0,
+		EnableRestart:         false,
+		ExponentialBackoff:    false,
+		MaxRestartDelay:       10 * time.Minute,
+		StableWindow:          10 * time.Minute,
+		EnableDegradedMode:    false,
+		DegradedRetryInterval: 5 * time.Minute,
+		UpgradeTimeout:        30 * time.Second,
 	}
 }
 
@@ -36,8 +38,29 @@ type Config struct {
 	// EnableRestart determines whether the restart mechanism for stopped or failed processes is enabled or disabled.
 	EnableRestart bool `mapstructure:"enable_restart"`
 
-	// ExponentialBackoff determines whether to apply exponential delay strategy for restarts.
+	// ExponentialBackoff determines whether to apply exponential delay strategy with decorrelated jitter for restarts.
 	ExponentialBackoff bool `mapstructure:"exponential_backoff"`
+
+	// MaxRestartDelay defines the upper bound of the restart delay when exponential backoff is enabled. Valid range: 1s to 1h.
+	MaxRestartDelay time.Duration `mapstructure:"max_restart_delay" validate:"min=1s,max=1h"`
+
+	// StableWindow defines the uptime after which the worker is considered stable and the restart counter is reset. Valid range: 1s to 24h.
+	StableWindow time.Duration `mapstructure:"stable_window" validate:"min=1s,max=24h"`
+
+	// EnableDegradedMode determines whether the runner keeps essential tasks running instead of exiting when restarts are exhausted.
+	EnableDegradedMode bool `mapstructure:"enable_degraded_mode"`
+
+	// DegradedRetryInterval defines how often a full restart is attempted in degraded mode. Valid range: 1s to 24h.
+	DegradedRetryInterval time.Duration `mapstructure:"degraded_retry_interval" validate:"min=1s,max=24h"`
+
+	// StateDir defines the directory where the runner persists its state between process restarts. Empty disables persistence.
+	StateDir string `mapstructure:"state_dir"`
+
+	// PIDFile defines the pidfile that also serves as the single-instance lock. Empty disables the lock.
+	PIDFile string `mapstructure:"pid_file"`
+
+	// UpgradeTimeout defines how long the new process may take to report readiness during a binary upgrade. Valid range: 1s to 10m.
+	UpgradeTimeout time.Duration `mapstructure:"upgrade_timeout" validate:"min=1s,max=10m"`
 }
 
 // Validate validates the Config object to ensure all fields comply with defined constraints and sets default values.
@@ -50,17 +73,21 @@ func (c *Config) Validate() error {
 	return c.validateRules()
 }
 
-// GetRestartDelay calculates and returns the restart delay based on the attempt number and exponential backoff settings.
-func (c *Config) GetRestartDelay(attempt int) time.Duration {
+// GetRestartDelay calculates the next restart delay based on the previous one. With exponential backoff enabled it uses
+// decorrelated jitter: a random delay between RestartDelay and three times the previous delay, capped by MaxRestartDelay.
+func (c *Config) GetRestartDelay(previous time.Duration) time.Duration {
 	if !c.ExponentialBackoff {
 		return c.RestartDelay
 	}
-	delay := c.RestartDelay * time.Duration(1<<attempt)
+	if previous < c.RestartDelay {
+		previous = c.RestartDelay
+	}
 
-	if delay > maxRestartDelay {
-		delay = maxRestartDelay
+	upper := min(previous*3, c.MaxRestartDelay)
+	if upper <= c.RestartDelay {
+		return upper
 	}
-	return delay
+	return c.RestartDelay + rand.N(upper-c.RestartDelay)
 }
 
 // setDefaults sets default values for Config fields if they are not already specified.
@@ -73,6 +100,18 @@ func (c *Config) setDefaults() {
 	if c.RestartDelay == 0 {
 		c.RestartDelay = defaults.RestartDelay
 	}
+	if c.MaxRestartDelay == 0 {
+		c.MaxRestartDelay = defaults.MaxRestartDelay
+	}
+	if c.StableWindow == 0 {
+		c.StableWindow = defaults.StableWindow
+	}
+	if c.DegradedRetryInterval == 0 {
+		c.DegradedRetryInterval = defaults.DegradedRetryInterval
+	}
+	if c.UpgradeTimeout == 0 {
+		c.UpgradeTimeout = defaults.UpgradeTimeout
+	}
 }
 
 // formatValidationErr processes validation errors for Config fields and returns detailed error messages.
@@ -88,6 +127,14 @@ func (c *Config) formatValidationErr(err error) error {
 				return initError("restart delay must be between 1s and 1m, got: %v", c.RestartDelay)
 			case "MaxRestarts":
 				return initError("max restarts must be between 0 and 100, got: %d", c.MaxRestarts)
+			case "MaxRestartDelay":
+				return initError("max restart delay must be between 1s and 1h, got: %v", c.MaxRestartDelay)
+			case "StableWindow":
+				return initError("stable window must be between 1s and 24h, got: %v", c.StableWindow)
+			case "DegradedRetryInterval":
+				return initError("degraded retry interval must be between 1s and 24h, got: %v", c.DegradedRetryInterval)
+			case "UpgradeTimeout":
+				return initError("upgrade timeout must be between 1s and 10m, got: %v", c.UpgradeTimeout)
 			default:
 				return initError("validation failed for field '%s': %s", fieldError.Field(), fieldError.Tag())
 			}
@@ -98,5 +145,8 @@ func (c *Config) formatValidationErr(err error) error {
 
 // validateRules performs additional custom validation logic for the Config struct to ensure its integrity.
 func (c *Config) validateRules() error {
+	if c.RestartDelay > c.MaxRestartDelay {
+		return initError("restart delay %v exceeds max restart delay %v", c.RestartDelay, c.MaxRestartDelay)
+	}
 	return nil
 }
@@ -15,6 +15,12 @@ const (
 	// ErrRunnerRestart is an error message format for failures occurring during the restart of a runner.
 	ErrRunnerRestart = "failed to restart runner: %s"
 
+	// ErrRunnerUpgrade is an error message format for failures occurring during the binary upgrade of a runner.
+	ErrRunnerUpgrade = "failed to upgrade runner: %s"
+
+	// ErrRunnerReload is an error message format for failures occurring while the config of a running runner is reloaded.
+	ErrRunnerReload = "failed to reload runner: %s"
+
 	// ErrWorkerManage represents a formatted error string for worker management-related failures.
 	ErrWorkerManage = "worker management error: %s"
 
@@ -38,6 +44,14 @@ func restartError(format string, args ...any) error {
 	return fmt.Errorf(ErrRunnerRestart, fmt.Sprintf(format, args...))
 }
 
+func upgradeError(format string, args ...any) error {
+	return fmt.Errorf(ErrRunnerUpgrade, fmt.Sprintf(format, args...))
+}
+
+func reloadError(format string, args ...any) error {
+	return fmt.Errorf(ErrRunnerReload, fmt.Sprintf(format, args...))
+}
+
 func workerManageError(format string, args ...any) error {
 	return fmt.Errorf(ErrWorkerManage, fmt.Sprintf(format, args...))
 }
@@ -0,0 +1,184 @@
+package runner
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/logger"
+	"go-ex-vm-agent/internal/worker"
+)
+
+// ReloadConfig конфигурация, которую runner применяет при перезагрузке
+type ReloadConfig struct {
+	Runner Config
+	Worker worker.Config
+	// LogLevel уровень логирования (пусто = не менять)
+	LogLevel logger.LogLevel
+}
+
+// ConfigLoader заново загружает конфигурацию при перезагрузке. Ошибка отменяет перезагрузку,
+// runner продолжает работать с текущей конфигурацией
+type ConfigLoader func() (ReloadConfig, error)
+
+// SetConfigLoader задает загрузчик конфигурации для перезагрузки, должен вызываться до Start.
+// Без загрузчика перезагрузка только пересоздает задачи через фабрику
+func (r *Runner) SetConfigLoader(loader ConfigLoader) {
+	r.configLoader = loader
+}
+
+// reload загружает конфигурацию, применяет ее и синхронизирует задачи worker'а с фабрикой.
+// Изменение конфигурации worker'а применяется перезапуском worker'а, иначе заменяются только изменившиеся задачи
+func (r *Runner) reload() error {
+	if r.configLoader == nil {
+		return r.reloadTasks()
+	}
+
+	cfg, err := r.configLoader()
+	if err != nil {
+		return reloadError("failed to load config: %v", err)
+	}
+	workerChanged, err := r.applyConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if workerChanged {
+		return r.reloadWorker()
+	}
+	return r.reloadTasks()
+}
+
+// applyConfig проверяет и применяет уровень логирования и конфигурацию runner'а и worker'а.
+// Возвращает true, если изменилась конфигурация worker'а
+func (r *Runner) applyConfig(cfg ReloadConfig) (bool, error) {
+	if err := cfg.Runner.Validate(); err != nil {
+		return false, reloadError("%s", err.Error())
+	}
+	if err := cfg.Worker.Validate(); err != nil {
+		return false, reloadError("worker config validation failed: %v", err)
+	}
+	if cfg.LogLevel != "" {
+		if err := logger.SetLevel(cfg.LogLevel); err != nil {
+			return false, reloadError("%s", err.Error())
+		}
+	}
+
+	// Блокировка экземпляра и файл состояния используются с момента запуска процесса
+	if cfg.Runner.PIDFile != r.config.PIDFile || cfg.Runner.StateDir != r.config.StateDir {
+		r.logger.Warn().Msg("pid_file and state_dir changes require a restart of the agent, keeping current values")
+		cfg.Runner.PIDFile = r.config.PIDFile
+		cfg.Runner.StateDir = r.config.StateDir
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = cfg.Runner
+	if reflect.DeepEqual(cfg.Worker, r.workerConfig) {
+		return false, nil
+	}
+	r.workerConfig = cfg.Worker
+	return true, nil
+}
+
+// reloadWorker перезапускает worker с новой конфигурацией. Рестарт не расходует бюджет рестартов.
+// В degraded режиме новая конфигурация применяется при восстановлении
+func (r *Runner) reloadWorker() error {
+	r.mu.RLock()
+	degraded := r.status == RunnerStatusDegraded
+	r.mu.RUnlock()
+	if degraded {
+		r.logger.Info().Msg("Worker config changed, it will be applied when the runner recovers from degraded mode")
+		return nil
+	}
+
+	r.logger.Info().Msg("Worker config changed, restarting worker")
+	r.shutdownWorker()
+	if err := r.startWorker(); err != nil {
+		r.mu.Lock()
+		r.lastError = err
+		r.mu.Unlock()
+
+		// Дальше worker восстанавливается по обычной политике рестартов
+		select {
+		case r.signals.restart <- struct{}{}:
+		default:
+		}
+		return reloadError("failed to restart worker with new config: %v", err)
+	}
+
+	r.logger.Info().Msg("Worker restarted with new config")
+	r.events.Publish(events.Event{
+		Type:       events.EventRunnerReloadApplied,
+		Attributes: map[string]string{"worker_restarted": "true"},
+	})
+	r.saveState()
+	return nil
+}
+
+// reloadTasks заново создает задачи через фабрику и применяет их к работающему worker'у:
+// новые задачи добавляются, отсутствующие удаляются, изменившиеся (см. worker.SameDefinition) заменяются
+func (r *Runner) reloadTasks() error {
+	w, err := r.currentWorker()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.ShutdownTimeout)
+	defer cancel()
+
+	tasks := r.taskFactory()
+	r.mu.RLock()
+	degraded := r.status == RunnerStatusDegraded
+	r.mu.RUnlock()
+	if degraded {
+		// В degraded режиме worker выполняет только essential задачи
+		tasks = essentialTasks(tasks)
+	}
+	current := w.GetTasksInfo()
+	added, replaced, unchanged, removed := 0, 0, 0, 0
+
+	for _, task := range tasks {
+		existing, exists := w.GetTask(task.Name())
+		if !exists {
+			if err := w.AddTask(task); err != nil {
+				return workerManageError("failed to add task '%s': %v", task.Name(), err)
+			}
+			added++
+			continue
+		}
+
+		delete(current, task.Name())
+		if worker.SameDefinition(existing, task) {
+			unchanged++
+			continue
+		}
+		if err := w.ReplaceTask(ctx, task); err != nil {
+			return workerManageError("failed to replace task '%s': %v", task.Name(), err)
+		}
+		replaced++
+	}
+	for name := range current {
+		if err := w.RemoveTask(ctx, name); err != nil {
+			return workerManageError("failed to remove task '%s': %v", name, err)
+		}
+		removed++
+	}
+
+	r.logger.Info().
+		Int("added", added).
+		Int("replaced", replaced).
+		Int("unchanged", unchanged).
+		Int("removed", removed).
+		Msg("Tasks reloaded")
+	r.events.Publish(events.Event{
+		Type: events.EventRunnerReloadApplied,
+		Attributes: map[string]string{
+			"added":     strconv.Itoa(added),
+			"replaced":  strconv.Itoa(replaced),
+			"unchanged": strconv.Itoa(unchanged),
+			"removed":   strconv.Itoa(removed),
+		},
+	})
+	return nil
+}
@@ -2,13 +2,18 @@ package runner
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/instance"
 	"go-ex-vm-agent/internal/logger"
+	"go-ex-vm-agent/internal/upgrade"
 	"go-ex-vm-agent/internal/worker"
 )
 
@@ -17,6 +22,8 @@ type Runner struct {
 	workerConfig worker.Config
 	logger       *logger.Logger
 	taskFactory  TaskFactory
+	configLoader ConfigLoader
+	events       *events.Bus
 
 	mu           sync.RWMutex
 	status       RunnerStatus
@@ -24,6 +31,25 @@ type Runner struct {
 	restartCount int
 	lastError    error
 
+	// workerStartedAt время последнего успешного запуска worker'а
+	workerStartedAt time.Time
+	// restartDelay задержка перед последним рестартом, база для decorrelated jitter
+	restartDelay time.Duration
+	// degradedReason причина перехода в degraded режим
+	degradedReason string
+
+	// bootID идентификатор загрузки системы, при которой запущен процесс
+	bootID string
+	// processStarts количество запусков процесса агента в рамках текущей загрузки системы
+	processStarts int
+	// taskLastSuccess время последнего успешного выполнения задач, в том числе из прошлых процессов
+	taskLastSuccess map[string]time.Time
+
+	// lock блокировка единственного экземпляра, nil если PIDFile не задан
+	lock *instance.Lock
+	// handedOver работа передана новому процессу при обновлении, состояние больше не сохраняется
+	handedOver bool
+
 	ctx    context.Context
 	cancel context.CancelFunc
 
@@ -33,7 +59,7 @@ type Runner struct {
 
 func New(config Config, workerConfig worker.Config, logger *logger.Logger, taskFactory TaskFactory) (*Runner, error) {
 	if err := config.Validate(); err != nil {
-		return nil, initError(err.Error())
+		return nil, initError("%s", err.Error())
 	}
 
 	if err := workerConfig.Validate(); err != nil {
@@ -55,11 +81,14 @@ func New(config Config, workerConfig worker.Config, logger *logger.Logger, taskF
 		workerConfig: workerConfig,
 		logger:       logger,
 		taskFactory:  taskFactory,
+		events:       events.NewBus(),
 		status:       RunnerStatusIdle,
 		ctx:          ctx,
 		cancel:       cancel,
 		signals:      newSignalHandler(),
 		doneCh:       make(chan struct{}),
+
+		taskLastSuccess: make(map[string]time.Time),
 	}, nil
 }
 
@@ -75,12 +104,31 @@ func (r *Runner) Start() error {
 
 	r.logger.Info().Msg("Starting runner")
 
+	// Блокировка захватывается до запуска задач и чтения состояния
+	if err := r.acquireLock(); err != nil {
+		r.mu.Lock()
+		r.status = RunnerStatusFailed
+		r.lastError = err
+		r.mu.Unlock()
+		return startError("%s", err.Error())
+	}
+
+	if err := r.restoreState(); err != nil {
+		r.mu.Lock()
+		r.status = RunnerStatusFailed
+		r.lastError = err
+		r.mu.Unlock()
+		r.releaseLock()
+		return startError("failed to restore state: %v", err)
+	}
+
 	// Устанавливаем обработку сигналов
 	if err := r.setupSignalHandling(); err != nil {
 		r.mu.Lock()
 		r.status = RunnerStatusFailed
 		r.lastError = err
 		r.mu.Unlock()
+		r.releaseLock()
 		return startError("failed to setup signal handling: %v", err)
 	}
 
@@ -90,6 +138,7 @@ func (r *Runner) Start() error {
 		r.status = RunnerStatusFailed
 		r.lastError = err
 		r.mu.Unlock()
+		r.releaseLock()
 		return startError("failed to start worker: %v", err)
 	}
 
@@ -97,8 +146,28 @@ func (r *Runner) Start() error {
 	r.status = RunnerStatusRunning
 	r.mu.Unlock()
 
+	// Процесс, запущенный при обновлении, сообщает предыдущему о готовности только после старта задач
+	if upgrade.IsUpgrade() {
+		if err := upgrade.NotifyReady(); err != nil {
+			r.shutdownWorker()
+			r.mu.Lock()
+			r.status = RunnerStatusFailed
+			r.lastError = err
+			r.mu.Unlock()
+			r.releaseLock()
+			return startError("%s", err.Error())
+		}
+		if r.lock != nil {
+			r.lock.Own()
+		}
+		r.logger.Info().Msg("Readiness reported to previous process")
+	}
+
 	r.logger.Info().Msg("Runner started successfully")
 
+	r.saveState()
+	go r.persistStateLoop()
+
 	// Запускаем основной цикл обработки
 	go r.run()
 
@@ -108,7 +177,7 @@ func (r *Runner) Start() error {
 // Stop выполняет graceful shutdown runner'а
 func (r *Runner) Stop() error {
 	r.mu.Lock()
-	if r.status != RunnerStatusRunning && r.status != RunnerStatusRestarting {
+	if r.status != RunnerStatusRunning && r.status != RunnerStatusRestarting && r.status != RunnerStatusDegraded {
 		currentStatus := r.status
 		r.mu.Unlock()
 		return stopError("runner is not running, current status: %s", currentStatus)
@@ -150,15 +219,60 @@ func (r *Runner) Restart() error {
 	}
 }
 
+// Reload запрашивает перезагрузку конфигурации через ConfigLoader и задач без перезапуска worker'а, см. SIGHUP
+func (r *Runner) Reload() error {
+	r.logger.Info().Msg("Reload requested")
+
+	select {
+	case r.signals.reload <- struct{}{}:
+		return nil
+	default:
+		return workerManageError("reload already in progress")
+	}
+}
+
+// Upgrade запускает новую версию бинарного файла и передает ей работу, см. SIGUSR2
+func (r *Runner) Upgrade() error {
+	r.mu.RLock()
+	status := r.status
+	r.mu.RUnlock()
+
+	if status != RunnerStatusRunning && status != RunnerStatusDegraded {
+		return upgradeError("runner is not running, current status: %s", status)
+	}
+
+	select {
+	case r.signals.upgrade <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Events возвращает шину событий задач и runner'а для регистрации подписчиков
+func (r *Runner) Events() *events.Bus {
+	return r.events
+}
+
 // GetInfo возвращает информацию о состоянии runner'а
 func (r *Runner) GetInfo() RunnerInfo {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	info := RunnerInfo{
-		Status:       r.status,
-		RestartCount: r.restartCount,
-		LastError:    r.lastError,
+		Status:         r.status,
+		RestartCount:   r.restartCount,
+		LastError:      r.lastError,
+		DegradedReason: r.degradedReason,
+		Events:         r.events.Stats(),
+		BootID:         r.bootID,
+		ProcessStarts:  r.processStarts,
+	}
+
+	if len(r.taskLastSuccess) > 0 {
+		info.TaskLastSuccess = make(map[string]time.Time, len(r.taskLastSuccess))
+		for name, t := range r.taskLastSuccess {
+			info.TaskLastSuccess[name] = t
+		}
 	}
 
 	if r.worker != nil {
@@ -169,6 +283,66 @@ func (r *Runner) GetInfo() RunnerInfo {
 	return info
 }
 
+// TriggerTask немедленно запускает выполнение задачи
+func (r *Runner) TriggerTask(name string) error {
+	w, err := r.currentWorker()
+	if err != nil {
+		return err
+	}
+	if err := w.TriggerTask(name); err != nil {
+		return workerManageError("%v", err)
+	}
+	return nil
+}
+
+// PauseTask приостанавливает плановые выполнения задачи
+func (r *Runner) PauseTask(name string) error {
+	w, err := r.currentWorker()
+	if err != nil {
+		return err
+	}
+	if err := w.PauseTask(name); err != nil {
+		return workerManageError("%v", err)
+	}
+	return nil
+}
+
+// ResumeTask возобновляет плановые выполнения задачи
+func (r *Runner) ResumeTask(name string) error {
+	w, err := r.currentWorker()
+	if err != nil {
+		return err
+	}
+	if err := w.ResumeTask(name); err != nil {
+		return workerManageError("%v", err)
+	}
+	return nil
+}
+
+// GetTaskHistory возвращает историю последних выполнений задачи
+func (r *Runner) GetTaskHistory(name string) ([]worker.ExecutionRecord, error) {
+	w, err := r.currentWorker()
+	if err != nil {
+		return nil, err
+	}
+	history, err := w.GetTaskHistory(name)
+	if err != nil {
+		return nil, workerManageError("%v", err)
+	}
+	return history, nil
+}
+
+// currentWorker возвращает текущий worker, если runner запущен
+func (r *Runner) currentWorker() (*worker.Worker, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.worker == nil {
+		return nil, workerManageError("worker is not running, runner status: %s", r.status)
+	}
+	return r.worker, nil
+}
+
 // Wait блокируется до завершения runner'а
 func (r *Runner) Wait() {
 	<-r.doneCh
@@ -191,6 +365,11 @@ func (r *Runner) setupSignalHandling() error {
 		signal.Notify(sigChan, syscall.SIGHUP)
 	}
 
+	// SIGUSR2 - upgrade binary (если поддерживается системой)
+	if supportsSignal(syscall.SIGUSR2) {
+		signal.Notify(sigChan, syscall.SIGUSR2)
+	}
+
 	go func() {
 		for {
 			select {
@@ -228,12 +407,36 @@ func (r *Runner) handleSystemSignal(sig os.Signal) {
 		case r.signals.reload <- struct{}{}:
 		default:
 		}
+	case syscall.SIGUSR2:
+		select {
+		case r.signals.upgrade <- struct{}{}:
+		default:
+		}
 	}
 }
 
 // run основной цикл runner'а
 func (r *Runner) run() {
 	defer close(r.doneCh)
+	defer r.releaseLock()
+
+	// degradedRetry срабатывает только в degraded режиме
+	var degradedRetry <-chan time.Time
+	var degradedTicker *time.Ticker
+	startDegradedTicker := func() {
+		if degradedTicker == nil {
+			degradedTicker = time.NewTicker(r.config.DegradedRetryInterval)
+			degradedRetry = degradedTicker.C
+		}
+	}
+	stopDegradedTicker := func() {
+		if degradedTicker != nil {
+			degradedTicker.Stop()
+			degradedTicker = nil
+			degradedRetry = nil
+		}
+	}
+	defer stopDegradedTicker()
 
 	for {
 		select {
@@ -252,27 +455,196 @@ func (r *Runner) run() {
 					Msg("Failed to restart worker")
 
 				if !r.config.EnableRestart || r.shouldStopRestarting() {
-					r.shutdownWorker()
-					return
+					if !r.config.EnableDegradedMode {
+						r.shutdownWorker()
+						return
+					}
+					r.enterDegradedMode(fmt.Sprintf("restart failed: %v", err))
+					startDegradedTicker()
 				}
+				continue
+			}
+			// Успешный рестарт (в том числе по SIGUSR1) выводит runner из degraded режима
+			stopDegradedTicker()
+
+		case reason := <-r.signals.degrade:
+			r.enterDegradedMode(reason)
+			startDegradedTicker()
+
+		case <-degradedRetry:
+			if r.recoverFromDegradedMode() {
+				stopDegradedTicker()
 			}
 
 		case <-r.signals.reload:
 			r.logger.Info().Msg("Config reload requested")
-			// TODO: реализовать перезагрузку конфигурации
+			if err := r.reload(); err != nil {
+				r.logger.Error().
+					Err(err).
+					Msg("Failed to reload config")
+			}
+
+		case <-r.signals.upgrade:
+			if err := r.upgradeBinary(); err != nil {
+				r.logger.Error().
+					Err(err).
+					Msg("Binary upgrade failed, continuing with current process")
+				continue
+			}
+			// Новый процесс готов - завершаем свои задачи и выходим
+			r.shutdownWorker()
+			r.mu.Lock()
+			r.status = RunnerStatusStopped
+			r.mu.Unlock()
+			return
+		}
+	}
+}
+
+// acquireLock захватывает блокировку единственного экземпляра, если задан PIDFile
+func (r *Runner) acquireLock() error {
+	if r.config.PIDFile == "" {
+		return nil
+	}
+
+	var (
+		lock *instance.Lock
+		err  error
+	)
+	if f := upgrade.Inherited(lockFileName); f != nil {
+		lock, err = instance.Adopt(r.config.PIDFile, f)
+	} else {
+		lock, err = instance.Acquire(r.config.PIDFile)
+	}
+	if err != nil {
+		return err
+	}
+	if pid := lock.StalePID(); pid != 0 {
+		r.logger.Warn().
+			Int("stale_pid", pid).
+			Str("pid_file", lock.Path()).
+			Msg("Previous instance exited without releasing pid file, taking over")
+	}
+
+	r.lock = lock
+	r.logger.Info().
+		Int("pid", os.Getpid()).
+		Str("pid_file", lock.Path()).
+		Msg("Instance lock acquired")
+	return nil
+}
+
+// releaseLock снимает блокировку единственного экземпляра
+func (r *Runner) releaseLock() {
+	if r.lock == nil {
+		return
+	}
+	if err := r.lock.Release(); err != nil {
+		r.logger.Error().
+			Err(err).
+			Msg("Failed to release instance lock")
+	}
+	r.lock = nil
+}
+
+// enterDegradedMode переводит runner в degraded режим: оставляет работать только
+// задачи с TaskOptions.Essential и периодически пытается выполнить полный рестарт
+func (r *Runner) enterDegradedMode(reason string) {
+	r.mu.Lock()
+	r.status = RunnerStatusDegraded
+	r.degradedReason = reason
+	r.mu.Unlock()
+
+	r.logger.Warn().
+		Str("reason", reason).
+		Dur("retry_interval", r.config.DegradedRetryInterval).
+		Msg("Runner entered degraded mode")
+	r.events.Publish(events.Event{
+		Type:       events.EventRunnerDegraded,
+		Attributes: map[string]string{"reason": reason},
+	})
+
+	r.shutdownWorker()
+
+	tasks := essentialTasks(r.taskFactory())
+	if len(tasks) == 0 {
+		r.logger.Warn().Msg("No essential tasks configured, degraded mode runs without worker")
+		return
+	}
+	if err := r.startWorkerWith(tasks); err != nil {
+		r.mu.Lock()
+		r.lastError = err
+		r.mu.Unlock()
+
+		r.logger.Error().
+			Err(err).
+			Msg("Failed to start essential tasks in degraded mode")
+	}
+	r.saveState()
+}
+
+// recoverFromDegradedMode пытается запустить все задачи. Возвращает true, если runner вернулся в рабочий режим
+func (r *Runner) recoverFromDegradedMode() bool {
+	r.logger.Info().Msg("Attempting full restart from degraded mode")
+
+	r.shutdownWorker()
+
+	if err := r.startWorker(); err != nil {
+		r.mu.Lock()
+		r.lastError = err
+		r.mu.Unlock()
+
+		r.logger.Error().
+			Err(err).
+			Msg("Full restart from degraded mode failed")
+
+		r.mu.RLock()
+		reason := r.degradedReason
+		r.mu.RUnlock()
+		r.enterDegradedMode(reason)
+		return false
+	}
+
+	// Счетчик рестартов не сбрасывается: это делает resetRestartsIfStable, когда worker
+	// проработает StableWindow, иначе повторный сбой сразу получил бы полный бюджет рестартов
+	r.mu.Lock()
+	r.status = RunnerStatusRunning
+	r.degradedReason = ""
+	r.lastError = nil
+	r.mu.Unlock()
+
+	r.logger.Info().Msg("Runner recovered from degraded mode")
+	r.events.Publish(events.Event{Type: events.EventRunnerRecovered})
+	r.saveState()
+	return true
+}
+
+// essentialTasks отбирает задачи, которые должны работать в degraded режиме
+func essentialTasks(tasks []worker.Task) []worker.Task {
+	var essential []worker.Task
+	for _, task := range tasks {
+		if t, ok := task.(worker.TaskWithOptions); ok && t.Options().Essential {
+			essential = append(essential, task)
 		}
 	}
+	return essential
 }
 
-// startWorker запускает worker с задачами
+// startWorker запускает worker со всеми задачами из фабрики
 func (r *Runner) startWorker() error {
+	return r.startWorkerWith(r.taskFactory())
+}
+
+// startWorkerWith запускает worker с указанными задачами
+func (r *Runner) startWorkerWith(tasks []worker.Task) error {
 	w, err := worker.New(r.workerConfig)
 	if err != nil {
 		return workerManageError("failed to create worker: %v", err)
 	}
 
+	w.SetEventBus(r.events)
+
 	// Регистрируем задачи
-	tasks := r.taskFactory()
 	for _, task := range tasks {
 		if err := w.RegisterTask(task); err != nil {
 			return workerManageError("failed to register task '%s': %v", task.Name(), err)
@@ -286,6 +658,7 @@ func (r *Runner) startWorker() error {
 
 	r.mu.Lock()
 	r.worker = w
+	r.workerStartedAt = time.Now()
 	r.mu.Unlock()
 
 	// Мониторим состояние worker'а
@@ -296,9 +669,14 @@ func (r *Runner) startWorker() error {
 
 // shutdownWorker останавливает worker
 func (r *Runner) shutdownWorker() {
-	r.mu.RLock()
+	// Сохраняем состояние, пока задачи worker'а еще доступны
+	r.saveState()
+
+	// Сбрасываем worker до остановки, чтобы monitorWorker не принял остановку за сбой
+	r.mu.Lock()
 	w := r.worker
-	r.mu.RUnlock()
+	r.worker = nil
+	r.mu.Unlock()
 
 	if w == nil {
 		return
@@ -314,30 +692,31 @@ func (r *Runner) shutdownWorker() {
 			Err(err).
 			Msg("Failed to shutdown worker gracefully")
 	}
-
-	r.mu.Lock()
-	r.worker = nil
-	r.mu.Unlock()
 }
 
 // restartWorker перезапускает worker
 func (r *Runner) restartWorker() error {
+	r.resetRestartsIfStable()
+
 	r.mu.Lock()
 	r.status = RunnerStatusRestarting
 	r.restartCount++
 	currentAttempt := r.restartCount
+	delay := r.config.GetRestartDelay(r.restartDelay)
+	r.restartDelay = delay
 	r.mu.Unlock()
 
 	r.logger.Info().
 		Int("attempt", currentAttempt).
 		Msg("Restarting worker")
+	r.events.Publish(events.Event{
+		Type:       events.EventRunnerRestarting,
+		Attributes: map[string]string{"attempt": strconv.Itoa(currentAttempt)},
+	})
 
 	// Останавливаем текущий worker
 	r.shutdownWorker()
 
-	// Вычисляем задержку с учетом exponential backoff
-	delay := r.config.GetRestartDelay(currentAttempt - 1) // attempt начинается с 0
-
 	if delay > 0 {
 		r.logger.Info().
 			Dur("delay", delay).
@@ -363,11 +742,18 @@ func (r *Runner) restartWorker() error {
 	r.mu.Lock()
 	r.status = RunnerStatusRunning
 	r.lastError = nil
+	recovered := r.degradedReason != ""
+	r.degradedReason = ""
 	r.mu.Unlock()
 
 	r.logger.Info().
 		Int("attempt", currentAttempt).
 		Msg("Worker restarted successfully")
+	if recovered {
+		r.logger.Info().Msg("Runner recovered from degraded mode")
+		r.events.Publish(events.Event{Type: events.EventRunnerRecovered})
+	}
+	r.saveState()
 
 	return nil
 }
@@ -384,15 +770,66 @@ func (r *Runner) monitorWorker() {
 
 	w.Wait()
 
-	// Worker завершился - проверяем нужен ли рестарт
-	if r.config.EnableRestart && !r.shouldStopRestarting() {
+	// Worker остановлен runner'ом (shutdown или restart) - рестарт не нужен
+	r.mu.RLock()
+	current := r.worker == w
+	r.mu.RUnlock()
+	if !current {
+		return
+	}
+
+	r.resetRestartsIfStable()
+
+	r.mu.RLock()
+	degraded := r.status == RunnerStatusDegraded
+	// Конфигурация может быть заменена при перезагрузке
+	config := r.config
+	r.mu.RUnlock()
+
+	switch {
+	case degraded:
+		// В degraded режиме восстановление выполняется по DegradedRetryInterval
+		r.logger.Warn().Msg("Essential tasks stopped in degraded mode")
+
+	case config.EnableRestart && !r.shouldStopRestarting():
+		// Worker завершился - перезапускаем
 		r.logger.Warn().Msg("Worker stopped unexpectedly, initiating restart")
 
 		select {
 		case r.signals.restart <- struct{}{}:
 		case <-r.ctx.Done():
 		}
+
+	case config.EnableDegradedMode:
+		r.logger.Warn().Msg("Worker stopped unexpectedly, restart budget exhausted")
+
+		select {
+		case r.signals.degrade <- "worker stopped and restart budget exhausted":
+		case <-r.ctx.Done():
+		}
+	}
+}
+
+// resetRestartsIfStable сбрасывает счетчик рестартов, если worker проработал дольше StableWindow
+func (r *Runner) resetRestartsIfStable() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.restartCount == 0 || r.workerStartedAt.IsZero() {
+		return
+	}
+
+	uptime := time.Since(r.workerStartedAt)
+	if uptime < r.config.StableWindow {
+		return
 	}
+
+	r.logger.Info().
+		Int("restart_count", r.restartCount).
+		Dur("uptime", uptime).
+		Msg("Worker was stable, resetting restart counter")
+	r.restartCount = 0
+	r.restartDelay = 0
 }
 
 // shouldStopRestarting проверяет, следует ли прекратить попытки рестарта
@@ -412,7 +849,7 @@ func (r *Runner) shouldStopRestarting() bool {
 func supportsSignal(sig syscall.Signal) bool {
 	// Простая проверка - на Windows не все UNIX сигналы поддерживаются
 	switch sig {
-	case syscall.SIGUSR1, syscall.SIGHUP:
+	case syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGHUP:
 		// Эти сигналы не поддерживаются на Windows
 		return os.Getenv("GOOS") != "windows"
 	default:
@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-ex-vm-agent/internal/fsutil"
+)
+
+const (
+	// stateFileName имя файла состояния в StateDir
+	stateFileName = "runner-state.json"
+
+	// stateSaveInterval период сохранения состояния во время работы
+	stateSaveInterval = 30 * time.Second
+
+	// bootIDPath файл с идентификатором текущей загрузки ядра (Linux)
+	bootIDPath = "/proc/sys/kernel/random/boot_id"
+)
+
+// persistentState состояние runner'а, которое сохраняется между перезапусками процесса
+type persistentState struct {
+	BootID         string    `json:"boot_id"`
+	PID            int       `json:"pid"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	ProcessStarts  int       `json:"process_starts"`
+	RestartCount   int       `json:"restart_count"`
+	LastError      string    `json:"last_error,omitempty"`
+	DegradedReason string    `json:"degraded_reason,omitempty"`
+	// TaskLastSuccess время последнего успешного выполнения по имени задачи
+	TaskLastSuccess map[string]time.Time `json:"task_last_success,omitempty"`
+}
+
+// statePath возвращает путь к файлу состояния или пустую строку, если сохранение выключено
+func (r *Runner) statePath() string {
+	r.mu.RLock()
+	dir := r.config.StateDir
+	r.mu.RUnlock()
+
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, stateFileName)
+}
+
+// restoreState загружает состояние предыдущего процесса. Счетчик рестартов переносится
+// только в пределах одной загрузки системы: после перезагрузки VM бюджет рестартов начинается заново
+func (r *Runner) restoreState() error {
+	path := r.statePath()
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(r.config.StateDir, 0o700); err != nil {
+		return initError("failed to create state dir '%s': %v", r.config.StateDir, err)
+	}
+
+	bootID := readBootID()
+	state := persistentState{TaskLastSuccess: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return initError("failed to read state file '%s': %v", path, err)
+	default:
+		if err := json.Unmarshal(data, &state); err != nil {
+			// Поврежденный файл не должен мешать запуску агента
+			r.logger.Warn().
+				Err(err).
+				Str("path", path).
+				Msg("Failed to parse state file, starting with empty state")
+			state = persistentState{TaskLastSuccess: make(map[string]time.Time)}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sameBoot := bootID != "" && state.BootID == bootID
+	if sameBoot {
+		r.processStarts = state.ProcessStarts + 1
+		r.restartCount = state.RestartCount
+	} else {
+		r.processStarts = 1
+	}
+	if state.LastError != "" {
+		r.lastError = errors.New(state.LastError)
+	}
+	if state.TaskLastSuccess != nil {
+		r.taskLastSuccess = state.TaskLastSuccess
+	}
+	r.bootID = bootID
+
+	r.logger.Info().
+		Str("boot_id", bootID).
+		Bool("same_boot", sameBoot).
+		Int("process_starts", r.processStarts).
+		Int("restart_count", r.restartCount).
+		Str("previous_degraded_reason", state.DegradedReason).
+		Msg("Runner state restored")
+	return nil
+}
+
+// saveState атомарно записывает текущее состояние runner'а в StateDir
+func (r *Runner) saveState() {
+	path := r.statePath()
+	if path == "" {
+		return
+	}
+
+	r.mu.Lock()
+	if r.handedOver {
+		// Состояние принадлежит новому процессу
+		r.mu.Unlock()
+		return
+	}
+	if r.worker != nil {
+		for name, info := range r.worker.GetTasksInfo() {
+			if info.LastSuccessAt != nil {
+				r.taskLastSuccess[name] = *info.LastSuccessAt
+			}
+		}
+	}
+
+	state := persistentState{
+		BootID:          r.bootID,
+		PID:             os.Getpid(),
+		UpdatedAt:       time.Now(),
+		ProcessStarts:   r.processStarts,
+		RestartCount:    r.restartCount,
+		DegradedReason:  r.degradedReason,
+		TaskLastSuccess: make(map[string]time.Time, len(r.taskLastSuccess)),
+	}
+	if r.lastError != nil {
+		state.LastError = r.lastError.Error()
+	}
+	for name, t := range r.taskLastSuccess {
+		state.TaskLastSuccess[name] = t
+	}
+	r.mu.Unlock()
+
+	if err := fsutil.WriteJSONAtomic(path, state, 0o600); err != nil {
+		r.logger.Warn().
+			Err(err).
+			Str("path", path).
+			Msg("Failed to save runner state")
+	}
+}
+
+// persistStateLoop периодически сохраняет состояние, пока runner работает
+func (r *Runner) persistStateLoop() {
+	if r.statePath() == "" {
+		return
+	}
+
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.saveState()
+		}
+	}
+}
+
+// readBootID возвращает идентификатор текущей загрузки системы или пустую строку, если он недоступен
+func readBootID() string {
+	data, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -1,6 +1,9 @@
 package runner
 
 import (
+	"time"
+
+	"go-ex-vm-agent/internal/events"
 	"go-ex-vm-agent/internal/worker"
 )
 
@@ -15,6 +18,7 @@ const (
 	RunnerStatusStopped    RunnerStatus = "stopped"
 	RunnerStatusRestarting RunnerStatus = "restarting"
 	RunnerStatusFailed     RunnerStatus = "failed"
+	RunnerStatusDegraded   RunnerStatus = "degraded"
 )
 
 // TaskFactory функция для создания задач
@@ -27,6 +31,15 @@ type RunnerInfo struct {
 	WorkerStatus worker.WorkerStatus
 	WorkerTasks  map[string]worker.TaskInfo
 	LastError    error
+	// DegradedReason причина degraded режима, пусто если runner не в degraded режиме
+	DegradedReason string
+	Events         events.Stats
+	// BootID идентификатор загрузки системы (пусто, если недоступен)
+	BootID string
+	// ProcessStarts количество запусков процесса агента в рамках текущей загрузки системы
+	ProcessStarts int
+	// TaskLastSuccess время последнего успешного выполнения задач, в том числе из прошлых процессов агента
+	TaskLastSuccess map[string]time.Time
 }
 
 // SignalAction тип действия при получении сигнала
@@ -36,6 +49,7 @@ const (
 	SignalActionShutdown SignalAction = "shutdown"
 	SignalActionRestart  SignalAction = "restart"
 	SignalActionReload   SignalAction = "reload"
+	SignalActionUpgrade  SignalAction = "upgrade"
 )
 
 // signalHandler внутренняя структура для обработки сигналов
@@ -43,6 +57,10 @@ type signalHandler struct {
 	shutdown chan struct{}
 	restart  chan struct{}
 	reload   chan struct{}
+	// upgrade запуск новой версии бинарного файла с передачей блокировки и сокетов
+	upgrade chan struct{}
+	// degrade переход в degraded режим с указанием причины
+	degrade chan string
 }
 
 func newSignalHandler() *signalHandler {
@@ -50,5 +68,7 @@ func newSignalHandler() *signalHandler {
 		shutdown: make(chan struct{}, 1),
 		restart:  make(chan struct{}, 1),
 		reload:   make(chan struct{}, 1),
+		upgrade:  make(chan struct{}, 1),
+		degrade:  make(chan string, 1),
 	}
 }
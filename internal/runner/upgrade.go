@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"os"
+	"strconv"
+
+	"go-ex-vm-agent/internal/events"
+	"go-ex-vm-agent/internal/upgrade"
+)
+
+// lockFileName имя, под которым дескриптор блокировки экземпляра передается новому процессу
+const lockFileName = "instance-lock"
+
+// upgradeBinary запускает текущий бинарный файл (уже замененный новой версией) и передает ему
+// блокировку экземпляра и слушающие сокеты. Если новый процесс не сообщил о готовности
+// за UpgradeTimeout, он останавливается, а текущий процесс продолжает работу
+func (r *Runner) upgradeBinary() error {
+	path, err := upgrade.Executable()
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info().
+		Str("executable", path).
+		Dur("timeout", r.config.UpgradeTimeout).
+		Msg("Starting binary upgrade")
+
+	// Новый процесс восстанавливает состояние из файла, поэтому сохраняем его до запуска
+	r.saveState()
+
+	var files []upgrade.File
+	if r.lock != nil {
+		files = append(files, upgrade.File{Name: lockFileName, File: r.lock.File()})
+	}
+
+	child, err := upgrade.Start(path, files)
+	if err != nil {
+		return r.upgradeFailed(err)
+	}
+
+	if err := child.WaitReady(r.ctx, r.config.UpgradeTimeout); err != nil {
+		r.logger.Warn().
+			Err(err).
+			Int("pid", child.PID()).
+			Msg("New process failed readiness, rolling back")
+
+		if abortErr := child.Abort(r.config.ShutdownTimeout); abortErr != nil {
+			r.logger.Error().
+				Err(abortErr).
+				Int("pid", child.PID()).
+				Msg("Failed to stop new process")
+		}
+		// Новый процесс успел записать в pidfile свой PID
+		if r.lock != nil {
+			if reclaimErr := r.lock.Reclaim(); reclaimErr != nil {
+				r.logger.Error().
+					Err(reclaimErr).
+					Msg("Failed to restore pid file")
+			}
+		}
+		return r.upgradeFailed(err)
+	}
+
+	r.mu.Lock()
+	r.handedOver = true
+	r.mu.Unlock()
+
+	// Блокировку удерживает новый процесс, закрываем только свой дескриптор
+	if r.lock != nil {
+		if err := r.lock.Close(); err != nil {
+			r.logger.Warn().
+				Err(err).
+				Msg("Failed to close instance lock after handover")
+		}
+		r.lock = nil
+	}
+
+	r.logger.Info().
+		Int("pid", os.Getpid()).
+		Int("new_pid", child.PID()).
+		Msg("New process is ready, draining tasks")
+	r.events.Publish(events.Event{
+		Type:       events.EventRunnerUpgraded,
+		Attributes: map[string]string{"new_pid": strconv.Itoa(child.PID())},
+	})
+	return nil
+}
+
+// upgradeFailed публикует событие неудачного обновления и возвращает err
+func (r *Runner) upgradeFailed(err error) error {
+	r.events.Publish(events.Event{
+		Type:  events.EventRunnerUpgradeFailed,
+		Error: err.Error(),
+	})
+	return err
+}
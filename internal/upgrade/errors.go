@@ -0,0 +1,13 @@
+package upgrade
+
+import "fmt"
+
+const (
+	// ErrUpgrade represents an error message format for failures during the binary upgrade handover.
+	ErrUpgrade = "upgrade error: %s"
+)
+
+// upgradeError supports %w, so callers get the underlying OS and process errors without wrapping them again.
+func upgradeError(format string, args ...any) error {
+	return fmt.Errorf(fmt.Sprintf(ErrUpgrade, format), args...)
+}
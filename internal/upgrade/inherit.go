@@ -0,0 +1,167 @@
+package upgrade
+
+import (
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	agent "go-ex-vm-agent"
+)
+
+// Переменные окружения, через которые родительский процесс описывает переданные дескрипторы
+var (
+	// envFiles список "имя=fd" через запятую
+	envFiles = agent.EnvPrefix + "_UPGRADE_FILES"
+	// envReadyFD дескриптор канала, в который новый процесс сообщает о готовности
+	envReadyFD = agent.EnvPrefix + "_UPGRADE_READY_FD"
+	// envAckFD дескриптор канала, через который предыдущий процесс подтверждает передачу
+	envAckFD = agent.EnvPrefix + "_UPGRADE_ACK_FD"
+)
+
+var (
+	inheritOnce sync.Once
+	inherited   map[string]*os.File
+	readyPipe   *os.File
+	ackPipe     *os.File
+
+	listenersMu sync.Mutex
+	listeners   = make(map[string]*os.File)
+)
+
+// loadInherited разбирает переменные окружения один раз и удаляет их, чтобы
+// дочерние процессы задач и следующее обновление их не унаследовали
+func loadInherited() {
+	inheritOnce.Do(func() {
+		inherited = make(map[string]*os.File)
+
+		for _, entry := range strings.Split(os.Getenv(envFiles), ",") {
+			name, fdStr, ok := strings.Cut(entry, "=")
+			if !ok {
+				continue
+			}
+			fd, err := strconv.Atoi(fdStr)
+			if err != nil || fd < 3 {
+				continue
+			}
+			inherited[name] = os.NewFile(uintptr(fd), name)
+		}
+		if fd, err := strconv.Atoi(os.Getenv(envReadyFD)); err == nil && fd >= 3 {
+			readyPipe = os.NewFile(uintptr(fd), "upgrade-ready")
+		}
+		if fd, err := strconv.Atoi(os.Getenv(envAckFD)); err == nil && fd >= 3 {
+			ackPipe = os.NewFile(uintptr(fd), "upgrade-ack")
+		}
+
+		_ = os.Unsetenv(envFiles)
+		_ = os.Unsetenv(envReadyFD)
+		_ = os.Unsetenv(envAckFD)
+	})
+}
+
+// IsUpgrade сообщает, запущен ли процесс предыдущим экземпляром в ходе обновления
+func IsUpgrade() bool {
+	loadInherited()
+	return readyPipe != nil
+}
+
+// Inherited возвращает дескриптор, переданный предыдущим процессом под именем name.
+// Каждый дескриптор выдается только один раз
+func Inherited(name string) *os.File {
+	loadInherited()
+
+	f := inherited[name]
+	delete(inherited, name)
+	return f
+}
+
+// NotifyReady сообщает предыдущему процессу, что новый процесс готов и тот может завершать задачи,
+// и ждет подтверждения передачи. Только после успешного возврата новый процесс может стать владельцем
+// унаследованной блокировки: ошибка означает, что предыдущий процесс отменил обновление и продолжает работу.
+// Если процесс запущен не в ходе обновления, ничего не делает
+func NotifyReady() error {
+	loadInherited()
+	if readyPipe == nil {
+		return nil
+	}
+	defer func() {
+		if ackPipe != nil {
+			ackPipe.Close()
+			ackPipe = nil
+		}
+	}()
+
+	_, err := readyPipe.Write([]byte{1})
+	closeErr := readyPipe.Close()
+	readyPipe = nil
+	if err != nil {
+		return upgradeError("failed to notify readiness: %w", err)
+	}
+	if closeErr != nil {
+		return upgradeError("failed to notify readiness: %w", closeErr)
+	}
+
+	if ackPipe == nil {
+		return upgradeError("previous process did not pass an acknowledgement pipe")
+	}
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(ackPipe, buf); err != nil {
+		return upgradeError("previous process cancelled the handover: %w", err)
+	}
+	return nil
+}
+
+// Listen возвращает слушающий сокет, унаследованный от предыдущего процесса, или создает новый.
+// Сокет запоминается и передается следующему процессу при обновлении
+func Listen(network, address string) (net.Listener, error) {
+	name := "listener:" + network + ":" + address
+
+	var (
+		l   net.Listener
+		err error
+	)
+	if f := Inherited(name); f != nil {
+		l, err = net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, upgradeError("failed to restore inherited listener %s: %w", name, err)
+		}
+	} else {
+		l, err = net.Listen(network, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filer, ok := l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return l, nil
+	}
+	f, err := filer.File()
+	if err != nil {
+		l.Close()
+		return nil, upgradeError("failed to duplicate listener %s: %w", name, err)
+	}
+
+	listenersMu.Lock()
+	if previous, exists := listeners[name]; exists {
+		previous.Close()
+	}
+	listeners[name] = f
+	listenersMu.Unlock()
+	return l, nil
+}
+
+// listenerFiles возвращает дескрипторы зарегистрированных через Listen сокетов
+func listenerFiles() []File {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	files := make([]File, 0, len(listeners))
+	for name, f := range listeners {
+		files = append(files, File{Name: name, File: f})
+	}
+	return files
+}
@@ -0,0 +1,166 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// File дескриптор, передаваемый новому процессу под именем Name
+type File struct {
+	Name string
+	File *os.File
+}
+
+// Child новый процесс агента, запущенный для обновления
+type Child struct {
+	cmd   *exec.Cmd
+	ready *os.File
+	// ack канал подтверждения передачи: новый процесс становится владельцем блокировки
+	// только после получения подтверждения, закрытие канала без него отменяет передачу
+	ack    *os.File
+	exited chan error
+}
+
+// Start запускает бинарный файл path с аргументами текущего процесса и передает ему files
+// вместе со всеми сокетами, созданными через Listen
+func Start(path string, files []File) (*Child, error) {
+	readyRead, readyWrite, err := os.Pipe()
+	if err != nil {
+		return nil, upgradeError("failed to create readiness pipe: %w", err)
+	}
+	defer readyWrite.Close()
+
+	ackRead, ackWrite, err := os.Pipe()
+	if err != nil {
+		readyRead.Close()
+		return nil, upgradeError("failed to create acknowledgement pipe: %w", err)
+	}
+	defer ackRead.Close()
+
+	files = append(files, listenerFiles()...)
+
+	// Дескрипторы ExtraFiles в новом процессе нумеруются с 3
+	extra := []*os.File{readyWrite, ackRead}
+	entries := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.File == nil {
+			continue
+		}
+		extra = append(extra, f.File)
+		entries = append(entries, fmt.Sprintf("%s=%d", f.Name, 2+len(extra)))
+	}
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = extra
+	cmd.Env = append(filterEnv(os.Environ()),
+		envReadyFD+"=3",
+		envAckFD+"=4",
+		envFiles+"="+strings.Join(entries, ","),
+	)
+
+	if err := cmd.Start(); err != nil {
+		readyRead.Close()
+		ackWrite.Close()
+		return nil, upgradeError("failed to start '%s': %w", path, err)
+	}
+
+	c := &Child{cmd: cmd, ready: readyRead, ack: ackWrite, exited: make(chan error, 1)}
+	go func() {
+		c.exited <- cmd.Wait()
+	}()
+	return c, nil
+}
+
+// PID возвращает PID нового процесса
+func (c *Child) PID() int {
+	return c.cmd.Process.Pid
+}
+
+// WaitReady ждет сообщения о готовности нового процесса и подтверждает ему передачу. Возвращает ошибку,
+// если процесс завершился, закрыл канал без сообщения или не успел за timeout. В этом случае передача
+// отменяется: новый процесс не становится владельцем блокировки, даже если сообщил о готовности позже
+func (c *Child) WaitReady(ctx context.Context, timeout time.Duration) error {
+	defer c.ready.Close()
+	defer c.ack.Close()
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := io.ReadFull(c.ready, buf)
+		ready <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			return upgradeError("new process %d exited before reporting ready", c.PID())
+		}
+		if _, err := c.ack.Write([]byte{1}); err != nil {
+			return upgradeError("failed to acknowledge readiness of new process %d: %w", c.PID(), err)
+		}
+		return nil
+	case err := <-c.exited:
+		return upgradeError("new process %d exited before reporting ready: %w", c.PID(), err)
+	case <-timer.C:
+		return upgradeError("new process %d did not report ready within %v", c.PID(), timeout)
+	case <-ctx.Done():
+		return upgradeError("upgrade cancelled: %w", ctx.Err())
+	}
+}
+
+// Abort завершает новый процесс после неудачного обновления
+func (c *Child) Abort(timeout time.Duration) error {
+	if err := c.cmd.Process.Signal(os.Interrupt); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return upgradeError("failed to stop new process %d: %w", c.PID(), err)
+	}
+
+	select {
+	case <-c.exited:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	if err := c.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return upgradeError("failed to kill new process %d: %w", c.PID(), err)
+	}
+	<-c.exited
+	return nil
+}
+
+// filterEnv убирает переменные обновления, оставшиеся от предыдущего запуска
+func filterEnv(env []string) []string {
+	filtered := env[:0:0]
+	for _, kv := range env {
+		if strings.HasPrefix(kv, envFiles+"=") || strings.HasPrefix(kv, envReadyFD+"=") ||
+			strings.HasPrefix(kv, envAckFD+"=") {
+			continue
+		}
+		filtered = append(filtered, kv)
+	}
+	return filtered
+}
+
+// Executable возвращает путь к бинарному файлу текущего процесса. Если файл был заменен
+// новой версией, путь указывает на новую версию
+func Executable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", upgradeError("failed to resolve executable: %w", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", upgradeError("executable '%s' is not available: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,243 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	agent "go-ex-vm-agent"
+	"sync"
+	"time"
+)
+
+type CircuitState string
+
+const (
+	CircuitStateClosed   CircuitState = "closed"
+	CircuitStateOpen     CircuitState = "open"
+	CircuitStateHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerConfig параметры circuit breaker'а задачи
+type CircuitBreakerConfig struct {
+	// FailureThreshold количество ошибок подряд, после которого breaker размыкается (по умолчанию 5)
+	FailureThreshold int
+	// SuccessThreshold количество успешных пробных выполнений в half-open для замыкания (по умолчанию 1)
+	SuccessThreshold int
+	// CoolDown время в состоянии open до пробного выполнения (по умолчанию 30s)
+	CoolDown time.Duration
+}
+
+// CircuitBreakerStats состояние и счетчики circuit breaker'а
+type CircuitBreakerStats struct {
+	State CircuitState
+	// Trips количество размыканий breaker'а
+	Trips int
+	// Rejected количество выполнений, пропущенных в состоянии open
+	Rejected int
+	// ChangedAt время последней смены состояния
+	ChangedAt *time.Time
+}
+
+// CircuitBreakerTask оборачивает задачу и пропускает выполнения ее handler'а,
+// пока зависимый сервис недоступен
+type CircuitBreakerTask struct {
+	Task
+	config CircuitBreakerConfig
+
+	mu        sync.Mutex
+	stats     CircuitBreakerStats
+	failures  int
+	successes int
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreakerTask оборачивает задачу circuit breaker'ом. Для периодических задач ошибка выполнения
+// учитывается breaker'ом и воркером, но не передается задаче, как при ContinueOnError: иначе TickerTask
+// завершился бы на первой ошибке и breaker никогда не разомкнулся бы
+func NewCircuitBreakerTask(task Task, config CircuitBreakerConfig) *CircuitBreakerTask {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 1
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+
+	return &CircuitBreakerTask{
+		Task:   task,
+		config: config,
+		stats:  CircuitBreakerStats{State: CircuitStateClosed},
+	}
+}
+
+// Unwrap возвращает исходную задачу
+func (t *CircuitBreakerTask) Unwrap() Task {
+	return t.Task
+}
+
+// Options возвращает параметры выполнения исходной задачи.
+// Для периодических задач ContinueOnError всегда включен
+func (t *CircuitBreakerTask) Options() TaskOptions {
+	var options TaskOptions
+	if task, ok := t.Task.(TaskWithOptions); ok {
+		options = task.Options()
+	}
+	if isSchedulable(t.Task) {
+		options.ContinueOnError = true
+	}
+	return options
+}
+
+func (t *CircuitBreakerTask) definition() any {
+	return t.config
+}
+
+// CircuitBreakerStats возвращает текущее состояние breaker'а
+func (t *CircuitBreakerTask) CircuitBreakerStats() CircuitBreakerStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+func (t *CircuitBreakerTask) Run(ctx context.Context) error {
+	parent, _ := ctx.Value(executorKey{}).(executor)
+	continueOnError := isSchedulable(t.Task) && !t.innerContinueOnError()
+
+	return t.Task.Run(withExecutor(ctx, func(ctx context.Context, handler HandlerFunc) error {
+		if !t.allow() {
+			agent.Logger.Debug().
+				Str("task", t.Name()).
+				Msg("Circuit breaker is open, execution skipped")
+			return nil
+		}
+
+		var err error
+		if parent != nil {
+			err = parent(ctx, handler)
+		} else {
+			err = handler(ctx)
+		}
+		// Пропущенное из-за паузы или прерванное выполнение не учитывается, но пробное выполнение освобождается
+		if ctx.Err() != nil || errors.Is(err, errExecutionSkipped) {
+			t.endProbe()
+			return err
+		}
+		t.record(err)
+		if continueOnError {
+			return nil
+		}
+		return err
+	}))
+}
+
+// innerContinueOnError возвращает ContinueOnError исходной задачи
+func (t *CircuitBreakerTask) innerContinueOnError() bool {
+	if task, ok := t.Task.(TaskWithOptions); ok {
+		return task.Options().ContinueOnError
+	}
+	return false
+}
+
+// inheritState переносит состояние и счетчики breaker'а заменяемой задачи
+func (t *CircuitBreakerTask) inheritState(old *CircuitBreakerTask) {
+	old.mu.Lock()
+	stats, failures, successes, openedAt := old.stats, old.failures, old.successes, old.openedAt
+	old.mu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = stats
+	t.failures = failures
+	t.successes = successes
+	t.openedAt = openedAt
+	t.probing = false
+}
+
+// allow проверяет, можно ли выполнить handler в текущем состоянии breaker'а
+func (t *CircuitBreakerTask) allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch t.stats.State {
+	case CircuitStateOpen:
+		if time.Since(t.openedAt) < t.config.CoolDown {
+			t.stats.Rejected++
+			return false
+		}
+		t.setState(CircuitStateHalfOpen)
+		t.successes = 0
+		t.probing = true
+		return true
+	case CircuitStateHalfOpen:
+		// В half-open допускается только одно пробное выполнение одновременно
+		if t.probing {
+			t.stats.Rejected++
+			return false
+		}
+		t.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// endProbe завершает пробное выполнение без учета результата
+func (t *CircuitBreakerTask) endProbe() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.probing = false
+}
+
+// record учитывает результат выполнения handler'а
+func (t *CircuitBreakerTask) record(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.probing = false
+
+	switch t.stats.State {
+	case CircuitStateHalfOpen:
+		if err != nil {
+			t.open()
+			return
+		}
+		t.successes++
+		if t.successes >= t.config.SuccessThreshold {
+			t.failures = 0
+			t.setState(CircuitStateClosed)
+		}
+	default:
+		if err == nil {
+			t.failures = 0
+			return
+		}
+		t.failures++
+		if t.failures >= t.config.FailureThreshold {
+			t.open()
+		}
+	}
+}
+
+func (t *CircuitBreakerTask) open() {
+	t.openedAt = time.Now()
+	t.stats.Trips++
+	t.setState(CircuitStateOpen)
+}
+
+func (t *CircuitBreakerTask) setState(state CircuitState) {
+	if t.stats.State == state {
+		return
+	}
+
+	now := time.Now()
+	agent.Logger.Warn().
+		Str("task", t.Name()).
+		Str("from", string(t.stats.State)).
+		Str("to", string(state)).
+		Msg("Circuit breaker state changed")
+
+	t.stats.State = state
+	t.stats.ChangedAt = &now
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsWithDefaultTickerOptions(t *testing.T) {
+	var calls atomic.Int32
+	task := NewCircuitBreakerTask(
+		NewTickerTask("ticker", 5*time.Millisecond, func(ctx context.Context) error {
+			calls.Add(1)
+			return errors.New("unavailable")
+		}),
+		CircuitBreakerConfig{FailureThreshold: 3, CoolDown: time.Hour},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := task.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want context deadline", err)
+	}
+
+	stats := task.CircuitBreakerStats()
+	if stats.State != CircuitStateOpen || stats.Trips != 1 {
+		t.Fatalf("stats = %+v, want one trip into open", stats)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("handler called %d times, want 3", got)
+	}
+	if stats.Rejected == 0 {
+		t.Fatal("no executions rejected while open")
+	}
+}
+
+func TestCircuitBreakerIgnoresSkippedExecutions(t *testing.T) {
+	task := NewCircuitBreakerTask(
+		NewOnceTask("once", func(ctx context.Context) error { return nil }),
+		CircuitBreakerConfig{FailureThreshold: 1},
+	)
+	task.failures = 0
+	task.stats.State = CircuitStateHalfOpen
+
+	skip := func(ctx context.Context, handler HandlerFunc) error { return errExecutionSkipped }
+	if err := task.Run(withExecutor(context.Background(), skip)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+	if task.stats.State != CircuitStateHalfOpen || task.successes != 0 {
+		t.Fatalf("skipped execution recorded: state %s, successes %d", task.stats.State, task.successes)
+	}
+	if task.probing {
+		t.Fatal("probe not released after skipped execution")
+	}
+}
+
+func TestCircuitBreakerReleasesProbeOnCancel(t *testing.T) {
+	started := make(chan struct{})
+	task := NewCircuitBreakerTask(
+		NewOnceTask("once", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		CircuitBreakerConfig{CoolDown: time.Millisecond},
+	)
+	task.stats.State = CircuitStateOpen
+	task.openedAt = time.Now().Add(-time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- task.Run(ctx) }()
+	<-started
+	cancel()
+	<-done
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+	if task.stats.State != CircuitStateHalfOpen {
+		t.Fatalf("state = %s, want half-open", task.stats.State)
+	}
+	if task.probing {
+		t.Fatal("probe not released after cancellation")
+	}
+}
@@ -13,18 +13,26 @@ func defaultConfig() Config {
 		TaskTimeout:     5 * time.Minute,
 		MaxTasks:        100,
 		StopOnError:     false,
+		HistorySize:     20,
+		HistoryFile:     "",
 	}
 }
 
 type Config struct {
 	// TaskStopTimeout максимальное время ожидания остановки одной задачи
 	TaskStopTimeout time.Duration `mapstructure:"task_stop_timeout" validate:"min=1s,max=2m"`
-	// TaskTimeout максимальное время выполнения задачи (0 = без лимита)
+	// TaskTimeout максимальное время одного выполнения handler'а задачи по умолчанию,
+	// задачи могут переопределить его через TaskOptions.ExecutionTimeout
 	TaskTimeout time.Duration `mapstructure:"task_timeout" validate:"min=0"`
-	// MaxTasks максимальное количество одновременно работающих задач
+	// MaxTasks максимальное количество одновременных выполнений задач,
+	// общий пул слотов для всех запусков handler'ов
 	MaxTasks int `mapstructure:"max_tasks" validate:"min=1,max=1000"`
 	// StopOnError останавливать ли воркер при ошибке в задаче
 	StopOnError bool `mapstructure:"stop_on_error"`
+	// HistorySize количество последних выполнений, хранимых для каждой задачи
+	HistorySize int `mapstructure:"history_size" validate:"min=1,max=1000"`
+	// HistoryFile файл для сохранения истории выполнений между перезапусками (пусто = не сохранять)
+	HistoryFile string `mapstructure:"history_file"`
 }
 
 func (c *Config) Validate() error {
@@ -48,6 +56,9 @@ func (c *Config) setDefaults() {
 	if c.MaxTasks == 0 {
 		c.MaxTasks = defaults.MaxTasks
 	}
+	if c.HistorySize == 0 {
+		c.HistorySize = defaults.HistorySize
+	}
 }
 
 func (c *Config) formatValidationErr(err error) error {
@@ -62,6 +73,8 @@ func (c *Config) formatValidationErr(err error) error {
 				return initError("task timeout must be non-negative, got: %v", c.TaskTimeout)
 			case "MaxTasks":
 				return initError("max tasks must be between 1 and 1000, got: %d", c.MaxTasks)
+			case "HistorySize":
+				return initError("history size must be between 1 and 1000, got: %d", c.HistorySize)
 			default:
 				return initError("validation failed for field '%s': %s", fieldError.Field(), fieldError.Tag())
 			}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	agent "go-ex-vm-agent"
+)
+
+type triggerKey struct{}
+
+// schedulable задача, которая выполняет handler многократно и поддерживает ручное управление
+type schedulable interface {
+	schedulable()
+}
+
+// isSchedulable проверяет, поддерживает ли задача (или задача внутри обертки) ручное управление
+func isSchedulable(task Task) bool {
+	for task != nil {
+		if _, ok := task.(schedulable); ok {
+			return true
+		}
+		wrapped, ok := task.(TaskWrapper)
+		if !ok {
+			return false
+		}
+		task = wrapped.Unwrap()
+	}
+	return false
+}
+
+func withTrigger(ctx context.Context, trigger <-chan struct{}) context.Context {
+	return context.WithValue(ctx, triggerKey{}, trigger)
+}
+
+// triggerChan возвращает канал ручного запуска задачи.
+// Если задача запущена не воркером, возвращается nil канал, который никогда не срабатывает
+func triggerChan(ctx context.Context) <-chan struct{} {
+	trigger, _ := ctx.Value(triggerKey{}).(<-chan struct{})
+	return trigger
+}
+
+// TriggerTask запускает выполнение задачи немедленно, вне расписания.
+// Работает и для приостановленной задачи - выполнение произойдет один раз
+func (w *Worker) TriggerTask(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wrapper, err := w.controllableTask(name)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case wrapper.trigger <- struct{}{}:
+	default:
+		// Запуск уже ожидает выполнения - повторные запросы объединяются
+	}
+	wrapper.triggered = true
+	wrapper.info.Status = TaskStatusTriggered
+
+	agent.Logger.Info().
+		Str("task", name).
+		Msg("Task triggered manually")
+	return nil
+}
+
+// PauseTask приостанавливает задачу: плановые выполнения пропускаются до вызова ResumeTask
+func (w *Worker) PauseTask(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wrapper, err := w.controllableTask(name)
+	if err != nil {
+		return err
+	}
+	if wrapper.paused {
+		return manageError("task '%s' is already paused", name)
+	}
+
+	wrapper.paused = true
+	if wrapper.info.Status == TaskStatusRunning {
+		wrapper.info.Status = TaskStatusPaused
+	}
+
+	agent.Logger.Info().
+		Str("task", name).
+		Msg("Task paused")
+	return nil
+}
+
+// ResumeTask возобновляет плановые выполнения приостановленной задачи
+func (w *Worker) ResumeTask(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wrapper, err := w.controllableTask(name)
+	if err != nil {
+		return err
+	}
+	if !wrapper.paused {
+		return manageError("task '%s' is not paused", name)
+	}
+
+	wrapper.paused = false
+	if wrapper.info.Status == TaskStatusPaused {
+		wrapper.info.Status = TaskStatusRunning
+	}
+
+	agent.Logger.Info().
+		Str("task", name).
+		Msg("Task resumed")
+	return nil
+}
+
+// controllableTask возвращает запущенную задачу, поддерживающую ручное управление.
+// Вызывается под блокировкой воркера
+func (w *Worker) controllableTask(name string) (*taskWrapper, error) {
+	wrapper, exists := w.tasks[name]
+	if !exists {
+		return nil, manageError("task '%s' not found", name)
+	}
+	if !isSchedulable(wrapper.task) {
+		return nil, manageError("task '%s' does not support manual control", name)
+	}
+
+	switch wrapper.info.Status {
+	case TaskStatusRunning, TaskStatusQueued, TaskStatusPaused, TaskStatusTriggered:
+		return wrapper, nil
+	default:
+		return nil, manageError("task '%s' is not running, current status: %s", name, wrapper.info.Status)
+	}
+}
+
+// beginExecution проверяет, нужно ли выполнять handler: приостановленная задача
+// выполняется только по ручному запуску
+func (w *Worker) beginExecution(wrapper *taskWrapper) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if wrapper.paused && !wrapper.triggered {
+		return false
+	}
+	wrapper.triggered = false
+	return true
+}
+
+// endExecution восстанавливает статус задачи после выполнения handler'а
+func (w *Worker) endExecution(wrapper *taskWrapper) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case wrapper.triggered:
+		wrapper.info.Status = TaskStatusTriggered
+	case wrapper.paused:
+		wrapper.info.Status = TaskStatusPaused
+	}
+}
@@ -0,0 +1,40 @@
+package worker
+
+import "reflect"
+
+// definable задача с параметрами запуска, которые участвуют в сравнении SameDefinition
+type definable interface {
+	definition() any
+}
+
+// SameDefinition проверяет, описывают ли задачи одно и то же: совпадают тип, имя, параметры выполнения
+// и параметры запуска (интервал, отслеживаемый файл, настройки circuit breaker'а), в том числе у обернутых
+// задач. Handler'ы и RetryPolicy.Retryable не сравниваются: функции считаются равными, только если обе nil
+func SameDefinition(a, b Task) bool {
+	for a != nil && b != nil {
+		if reflect.TypeOf(a) != reflect.TypeOf(b) || a.Name() != b.Name() {
+			return false
+		}
+		if !reflect.DeepEqual(taskOptions(a), taskOptions(b)) {
+			return false
+		}
+		if da, ok := a.(definable); ok && !reflect.DeepEqual(da.definition(), b.(definable).definition()) {
+			return false
+		}
+
+		wa, ok := a.(TaskWrapper)
+		if !ok {
+			return true
+		}
+		a, b = wa.Unwrap(), b.(TaskWrapper).Unwrap()
+	}
+	return a == nil && b == nil
+}
+
+// taskOptions возвращает собственные параметры выполнения задачи без значений по умолчанию воркера
+func taskOptions(task Task) TaskOptions {
+	if t, ok := task.(TaskWithOptions); ok {
+		return t.Options()
+	}
+	return TaskOptions{}
+}
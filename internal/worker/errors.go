@@ -9,6 +9,7 @@ const (
 	ErrTaskRegistration = "failed to register task: %s"
 	ErrTaskExecution    = "task execution error: %s"
 	ErrTaskTimeout      = "task timeout: %s"
+	ErrTaskManage       = "task management error: %s"
 )
 
 func initError(format string, args ...any) error {
@@ -34,3 +35,7 @@ func executionError(format string, args ...any) error {
 func timeoutError(format string, args ...any) error {
 	return fmt.Errorf(ErrTaskTimeout, fmt.Sprintf(format, args...))
 }
+
+func manageError(format string, args ...any) error {
+	return fmt.Errorf(ErrTaskManage, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	agent "go-ex-vm-agent"
+	"go-ex-vm-agent/internal/events"
+	"time"
+)
+
+type executorKey struct{}
+
+// errExecutionSkipped возвращается executor'ом воркера, если выполнение пропущено из-за паузы задачи.
+// Обертки по нему отличают пропуск от успешного выполнения, execute заменяет его на nil
+var errExecutionSkipped = errors.New("execution skipped")
+
+type failuresKey struct{}
+
+// executor выполняет один вызов handler'а задачи под контролем воркера
+type executor func(ctx context.Context, handler HandlerFunc) error
+
+func withExecutor(ctx context.Context, exec executor) context.Context {
+	return context.WithValue(ctx, executorKey{}, exec)
+}
+
+func withFailures(ctx context.Context, failures func() int) context.Context {
+	return context.WithValue(ctx, failuresKey{}, failures)
+}
+
+// consecutiveFailures возвращает TaskInfo.ConsecutiveFailures задачи.
+// Если задача запущена не воркером, счетчик не ведется и возвращается 0
+func consecutiveFailures(ctx context.Context) int {
+	if failures, ok := ctx.Value(failuresKey{}).(func() int); ok {
+		return failures()
+	}
+	return 0
+}
+
+// execute выполняет handler через executor воркера,
+// если задача запущена воркером, иначе вызывает handler напрямую
+func execute(ctx context.Context, handler HandlerFunc) error {
+	if exec, ok := ctx.Value(executorKey{}).(executor); ok {
+		if err := exec(ctx, handler); !errors.Is(err, errExecutionSkipped) {
+			return err
+		}
+		return nil
+	}
+	return handler(ctx)
+}
+
+// newExecutor создает executor для конкретной задачи
+func (w *Worker) newExecutor(wrapper *taskWrapper) executor {
+	return func(ctx context.Context, handler HandlerFunc) error {
+		if !w.beginExecution(wrapper) {
+			agent.Logger.Debug().
+				Str("task", wrapper.task.Name()).
+				Msg("Task is paused, execution skipped")
+			return errExecutionSkipped
+		}
+
+		defer w.endExecution(wrapper)
+
+		err := w.executeWithRetry(ctx, wrapper, handler)
+		if ctx.Err() == nil {
+			w.updateInfo(wrapper, func(info *TaskInfo) {
+				if err == nil {
+					now := time.Now()
+					info.LastSuccessAt = &now
+					info.ConsecutiveFailures = 0
+					return
+				}
+				info.ConsecutiveFailures++
+				info.TotalFailures++
+			})
+		}
+		return err
+	}
+}
+
+// executeWithRetry выполняет handler с повторными попытками согласно политике задачи
+func (w *Worker) executeWithRetry(ctx context.Context, wrapper *taskWrapper, handler HandlerFunc) error {
+	retry := wrapper.options.Retry
+	for attempt := 1; ; attempt++ {
+		if err := w.acquireSlot(ctx, wrapper); err != nil {
+			return err
+		}
+		err := w.run(ctx, wrapper, handler, attempt)
+		w.limiter.release()
+
+		if err == nil || ctx.Err() != nil || !retry.shouldRetry(attempt, err) {
+			return err
+		}
+
+		delay := retry.delay(attempt)
+		agent.Logger.Warn().
+			Err(err).
+			Str("task", wrapper.task.Name()).
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Msg("Task execution attempt failed, retrying")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// run выполняет одну попытку handler'а с таймаутом выполнения и записывает результат в историю задачи
+func (w *Worker) run(ctx context.Context, wrapper *taskWrapper, handler HandlerFunc, attempt int) error {
+	output := &outputBuffer{}
+	execCtx := context.WithValue(ctx, outputKey{}, output)
+
+	timeout := wrapper.options.ExecutionTimeout
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(execCtx, timeout)
+		defer cancel()
+	}
+
+	record := ExecutionRecord{StartedAt: time.Now(), Attempt: attempt}
+	err := handler(execCtx)
+	record.EndedAt = time.Now()
+	record.Duration = record.EndedAt.Sub(record.StartedAt)
+
+	switch {
+	case err == nil:
+		record.Outcome = ExecutionOutcomeSuccess
+	case ctx.Err() != nil:
+		record.Outcome = ExecutionOutcomeCanceled
+	case timeout > 0 && errors.Is(execCtx.Err(), context.DeadlineExceeded):
+		record.Outcome = ExecutionOutcomeTimeout
+		err = timeoutError("task '%s' execution timeout %v exceeded", wrapper.task.Name(), timeout)
+		w.publish(events.EventTaskTimedOut, wrapper, err, map[string]string{"scope": "execution"})
+	default:
+		record.Outcome = ExecutionOutcomeFailed
+		// Задача может продолжить работу после ошибки (ContinueOnError, повторные попытки),
+		// поэтому об ошибке выполнения сообщается отдельно от завершения задачи
+		w.publish(events.EventTaskFailed, wrapper, err, map[string]string{"scope": "execution"})
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	record.Output = output.String()
+
+	w.recordExecution(wrapper, record)
+	return err
+}
+
+// acquireSlot занимает слот выполнения, пока слота нет - задача находится в статусе queued
+func (w *Worker) acquireSlot(ctx context.Context, wrapper *taskWrapper) error {
+	if w.limiter.tryAcquire() {
+		w.updateInfo(wrapper, func(info *TaskInfo) {
+			info.Status = TaskStatusRunning
+			info.LastQueueTime = 0
+		})
+		return nil
+	}
+
+	queuedAt := time.Now()
+	w.updateInfo(wrapper, func(info *TaskInfo) {
+		info.Status = TaskStatusQueued
+	})
+	agent.Logger.Debug().
+		Str("task", wrapper.task.Name()).
+		Int("priority", wrapper.options.Priority).
+		Msg("Task execution queued")
+
+	err := w.limiter.acquire(ctx, wrapper.options.Priority)
+	queueTime := time.Since(queuedAt)
+
+	w.updateInfo(wrapper, func(info *TaskInfo) {
+		info.Status = TaskStatusRunning
+		info.LastQueueTime = queueTime
+		info.TotalQueueTime += queueTime
+	})
+	return err
+}
@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	agent "go-ex-vm-agent"
+	"go-ex-vm-agent/internal/fsutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxOutputLength максимальная длина фрагмента вывода, сохраняемого в истории
+const maxOutputLength = 256
+
+type ExecutionOutcome string
+
+const (
+	ExecutionOutcomeSuccess  ExecutionOutcome = "success"
+	ExecutionOutcomeFailed   ExecutionOutcome = "failed"
+	ExecutionOutcomeTimeout  ExecutionOutcome = "timeout"
+	ExecutionOutcomeCanceled ExecutionOutcome = "canceled"
+)
+
+// ExecutionRecord запись об одном выполнении handler'а задачи
+type ExecutionRecord struct {
+	StartedAt time.Time        `json:"started_at"`
+	EndedAt   time.Time        `json:"ended_at"`
+	Duration  time.Duration    `json:"duration"`
+	Attempt   int              `json:"attempt"`
+	Outcome   ExecutionOutcome `json:"outcome"`
+	Error     string           `json:"error,omitempty"`
+	Output    string           `json:"output,omitempty"`
+}
+
+// executionHistory кольцевой буфер последних выполнений задачи
+type executionHistory struct {
+	records []ExecutionRecord
+	next    int
+	full    bool
+}
+
+func newExecutionHistory(size int, records []ExecutionRecord) *executionHistory {
+	h := &executionHistory{records: make([]ExecutionRecord, size)}
+	for _, record := range records {
+		h.add(record)
+	}
+	return h
+}
+
+func (h *executionHistory) add(record ExecutionRecord) {
+	if len(h.records) == 0 {
+		return
+	}
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list возвращает записи от самой старой к самой новой. Для задачи без выполнений
+// возвращается пустой срез, а не nil, чтобы в HistoryFile попадал [], а не null
+func (h *executionHistory) list() []ExecutionRecord {
+	if !h.full {
+		return append(make([]ExecutionRecord, 0, h.next), h.records[:h.next]...)
+	}
+	records := make([]ExecutionRecord, 0, len(h.records))
+	records = append(records, h.records[h.next:]...)
+	return append(records, h.records[:h.next]...)
+}
+
+type outputKey struct{}
+
+// outputBuffer фрагмент вывода текущего выполнения
+type outputBuffer struct {
+	mu     sync.Mutex
+	output string
+}
+
+// SetOutput сохраняет короткий фрагмент вывода текущего выполнения в историю задачи.
+// Длинный вывод обрезается до maxOutputLength символов
+func SetOutput(ctx context.Context, output string) {
+	buf, ok := ctx.Value(outputKey{}).(*outputBuffer)
+	if !ok {
+		return
+	}
+	if runes := []rune(output); len(runes) > maxOutputLength {
+		output = string(runes[:maxOutputLength])
+	}
+
+	buf.mu.Lock()
+	buf.output = output
+	buf.mu.Unlock()
+}
+
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.output
+}
+
+// GetTaskHistory возвращает последние выполнения задачи, от самого старого к самому новому
+func (w *Worker) GetTaskHistory(name string) ([]ExecutionRecord, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	wrapper, exists := w.tasks[name]
+	if !exists {
+		return nil, manageError("task '%s' not found", name)
+	}
+	return wrapper.history.list(), nil
+}
+
+// recordExecution добавляет запись о выполнении в историю задачи
+func (w *Worker) recordExecution(wrapper *taskWrapper, record ExecutionRecord) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wrapper.history.add(record)
+}
+
+// loadHistory читает сохраненную историю выполнений из HistoryFile
+func loadHistory(path string) (map[string][]ExecutionRecord, error) {
+	history := make(map[string][]ExecutionRecord)
+	if path == "" {
+		return history, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// saveHistory атомарно записывает историю выполнений в HistoryFile. История незарегистрированных задач
+// (например, не essential задач в degraded режиме runner'а) записывается такой, какой была загружена
+func (w *Worker) saveHistory() {
+	if w.config.HistoryFile == "" {
+		return
+	}
+
+	w.mu.RLock()
+	history := make(map[string][]ExecutionRecord, len(w.savedHistory)+len(w.tasks))
+	for name, records := range w.savedHistory {
+		history[name] = records
+	}
+	for name, wrapper := range w.tasks {
+		history[name] = wrapper.history.list()
+	}
+	w.mu.RUnlock()
+
+	if err := fsutil.WriteJSONAtomic(w.config.HistoryFile, history, 0o600); err != nil {
+		agent.Logger.Warn().
+			Err(err).
+			Str("path", w.config.HistoryFile).
+			Msg("Failed to save task history")
+	}
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-ex-vm-agent/internal/fsutil"
+)
+
+func TestSaveHistoryKeepsUnregisteredTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	saved := map[string][]ExecutionRecord{
+		"essential": {{Attempt: 1, Outcome: ExecutionOutcomeSuccess}},
+		"other":     {{Attempt: 1, Outcome: ExecutionOutcomeFailed, Error: "unavailable"}},
+	}
+	if err := fsutil.WriteJSONAtomic(path, saved, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(Config{MaxTasks: 1, TaskTimeout: time.Second, TaskStopTimeout: time.Second, HistorySize: 10, HistoryFile: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := w.RegisterTask(NewOnceTask("essential", func(ctx context.Context) error { return nil })); err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitForTaskStatus(t, w, "essential", TaskStatusCompleted)
+	if err := w.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	history, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory() error = %v", err)
+	}
+	if len(history["essential"]) != 2 {
+		t.Errorf("history of the registered task = %+v, want the saved and the new execution", history["essential"])
+	}
+	if len(history["other"]) != 1 || history["other"][0].Error != "unavailable" {
+		t.Errorf("history of the unregistered task = %+v, want it kept", history["other"])
+	}
+}
+
+func TestRemovedTaskHistoryIsSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	w, err := New(Config{MaxTasks: 1, TaskTimeout: time.Second, TaskStopTimeout: time.Second, HistorySize: 10, HistoryFile: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, task := range []Task{NewBaseTask("base"), NewOnceTask("once", func(ctx context.Context) error { return nil })} {
+		if err := w.RegisterTask(task); err != nil {
+			t.Fatalf("RegisterTask() error = %v", err)
+		}
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop(context.Background())
+	waitForTaskStatus(t, w, "once", TaskStatusCompleted)
+
+	if err := w.RemoveTask(context.Background(), "once"); err != nil {
+		t.Fatalf("RemoveTask() error = %v", err)
+	}
+	w.saveHistory()
+	history, err := loadHistory(path)
+	if err != nil || len(history["once"]) != 1 {
+		t.Fatalf("saved history = %+v, %v, want the execution of the removed task", history, err)
+	}
+
+	if err := w.AddTask(NewBaseTask("once")); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	if records, _ := w.GetTaskHistory("once"); len(records) != 1 {
+		t.Fatalf("history of the added task = %+v, want the history of the removed one", records)
+	}
+}
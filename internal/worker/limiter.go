@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// limiter ограничивает количество одновременных выполнений задач.
+// Слоты выдаются ожидающим в порядке приоритета, при равном приоритете - в порядке очереди.
+type limiter struct {
+	mu      sync.Mutex
+	slots   int
+	active  int
+	seq     uint64
+	waiters waitQueue
+}
+
+func newLimiter(slots int) *limiter {
+	return &limiter{slots: slots}
+}
+
+// tryAcquire занимает слот, если он свободен и нет ожидающих
+func (l *limiter) tryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active < l.slots && len(l.waiters) == 0 {
+		l.active++
+		return true
+	}
+	return false
+}
+
+// acquire блокируется до получения слота или отмены контекста
+func (l *limiter) acquire(ctx context.Context, priority int) error {
+	l.mu.Lock()
+	if l.active < l.slots && len(l.waiters) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+
+	l.seq++
+	wt := &waiter{
+		priority: priority,
+		seq:      l.seq,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&l.waiters, wt)
+	l.mu.Unlock()
+
+	select {
+	case <-wt.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if wt.index >= 0 {
+			heap.Remove(&l.waiters, wt.index)
+			return ctx.Err()
+		}
+		// Слот уже был выдан - передаем его следующему
+		l.releaseLocked()
+		return ctx.Err()
+	}
+}
+
+// release освобождает слот
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+func (l *limiter) releaseLocked() {
+	if len(l.waiters) > 0 {
+		wt := heap.Pop(&l.waiters).(*waiter)
+		close(wt.ready)
+		return
+	}
+	l.active--
+}
+
+// waiter ожидающее слот выполнение
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+// waitQueue очередь ожидающих выполнений, реализует heap.Interface
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int {
+	return len(q)
+}
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	wt := x.(*waiter)
+	wt.index = len(*q)
+	*q = append(*q, wt)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	wt := old[n-1]
+	old[n-1] = nil
+	wt.index = -1
+	*q = old[:n-1]
+	return wt
+}
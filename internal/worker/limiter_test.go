@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterGrantsSlotsByPriority(t *testing.T) {
+	l := newLimiter(1)
+	if err := l.acquire(context.Background(), 0); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	order := make(chan string, 3)
+	waiters := 0
+	enqueue := func(name string, priority int) {
+		go func() {
+			if err := l.acquire(context.Background(), priority); err != nil {
+				t.Errorf("acquire(%s) error = %v", name, err)
+				return
+			}
+			order <- name
+			l.release()
+		}()
+		waiters++
+		waitForWaiters(t, l, waiters)
+	}
+	enqueue("low", 1)
+	enqueue("high", 10)
+	enqueue("low-second", 1)
+
+	l.release()
+	for _, want := range []string{"high", "low", "low-second"} {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("slot granted to %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("slot was not granted to %s", want)
+		}
+	}
+}
+
+func TestLimiterCancelledWaiterLeavesQueue(t *testing.T) {
+	l := newLimiter(1)
+	if !l.tryAcquire() {
+		t.Fatal("tryAcquire() failed on an empty limiter")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.acquire(ctx, 0) }()
+	waitForWaiters(t, l, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire() error = %v, want context.Canceled", err)
+	}
+
+	l.release()
+	if !l.tryAcquire() {
+		t.Fatal("slot was not released after the waiter cancelled")
+	}
+}
+
+// waitForWaiters ждет, пока в очереди limiter'а окажется want ожидающих
+func waitForWaiters(t *testing.T, l *limiter, want int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		n := len(l.waiters)
+		l.mu.Unlock()
+		if n >= want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("limiter has fewer than %d waiters", want)
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+type BackoffStrategy string
+
+const (
+	BackoffConstant    BackoffStrategy = "constant"
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// RetryPolicy политика повторных попыток handler'а внутри одного выполнения задачи
+type RetryPolicy struct {
+	// MaxAttempts общее количество попыток, включая первую
+	MaxAttempts int
+	// Backoff стратегия увеличения задержки между попытками (по умолчанию constant)
+	Backoff BackoffStrategy
+	// Delay задержка перед второй попыткой
+	Delay time.Duration
+	// MaxDelay максимальная задержка между попытками (0 = без лимита)
+	MaxDelay time.Duration
+	// Jitter доля случайного разброса задержки, от 0 до 1
+	Jitter float64
+	// Retryable определяет, стоит ли повторять попытку после ошибки (nil = любая ошибка)
+	Retryable func(err error) bool
+}
+
+// shouldRetry проверяет, нужна ли еще одна попытка после неудачной попытки attempt
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return true
+}
+
+// delay вычисляет задержку перед попыткой, следующей за попыткой attempt
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Delay
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt && delay < math.MaxInt64/2; i++ {
+			delay *= 2
+			if p.MaxDelay > 0 && delay >= p.MaxDelay {
+				break
+			}
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 && delay > 0 {
+		jitter := min(p.Jitter, 1)
+		spread := float64(delay) * jitter
+		delay = time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+	}
+	return delay
+}
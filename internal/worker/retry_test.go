@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     BackoffExponential,
+		Delay:       100 * time.Millisecond,
+		MaxDelay:    300 * time.Millisecond,
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, delay := range want {
+		if got := policy.delay(i + 1); got != delay {
+			t.Errorf("delay(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for range 100 {
+		if got := policy.delay(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("delay(1) with jitter = %v, want within 50ms..150ms", got)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	permanent := errors.New("permanent")
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return !errors.Is(err, permanent) },
+	}
+
+	if !policy.shouldRetry(1, errors.New("temporary")) {
+		t.Error("temporary error after the first attempt is not retried")
+	}
+	if policy.shouldRetry(3, errors.New("temporary")) {
+		t.Error("error after the last attempt is retried")
+	}
+	if policy.shouldRetry(1, permanent) {
+		t.Error("error rejected by Retryable is retried")
+	}
+	if (*RetryPolicy)(nil).shouldRetry(1, errors.New("temporary")) {
+		t.Error("nil policy retries")
+	}
+}
+
+func TestWorkerRetriesWithinOneExecution(t *testing.T) {
+	w, err := New(Config{MaxTasks: 1, TaskTimeout: time.Second, TaskStopTimeout: time.Second, HistorySize: 10})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	attempts := 0
+	task := NewOnceTask("once", func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}).WithOptions(TaskOptions{Retry: &RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond}})
+	if err := w.RegisterTask(task); err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop(context.Background())
+	waitForTaskStatus(t, w, "once", TaskStatusCompleted)
+
+	if attempts != 3 {
+		t.Fatalf("handler called %d times, want 3", attempts)
+	}
+	info := w.GetTasksInfo()["once"]
+	if info.ConsecutiveFailures != 0 || info.TotalFailures != 0 || info.LastSuccessAt == nil {
+		t.Fatalf("info = %+v, want one successful execution", info)
+	}
+	history, err := w.GetTaskHistory("once")
+	if err != nil {
+		t.Fatalf("GetTaskHistory() error = %v", err)
+	}
+	if len(history) != 3 || history[2].Attempt != 3 {
+		t.Fatalf("history = %+v, want three attempts", history)
+	}
+}
+
+// waitForTaskStatus ждет, пока задача перейдет в статус status
+func waitForTaskStatus(t *testing.T, w *Worker, name string, status TaskStatus) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if w.GetTasksInfo()[name].Status == status {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("task '%s' status = %s, want %s", name, w.GetTasksInfo()[name].Status, status)
+}
@@ -2,12 +2,14 @@ package worker
 
 import (
 	"context"
+	agent "go-ex-vm-agent"
 	"time"
 )
 
 // BaseTask предоставляет базовую реализацию Task интерфейса
 type BaseTask struct {
-	name string
+	name    string
+	options TaskOptions
 }
 
 func NewBaseTask(name string) *BaseTask {
@@ -18,6 +20,17 @@ func (t *BaseTask) Name() string {
 	return t.name
 }
 
+// Options возвращает параметры выполнения задачи
+func (t *BaseTask) Options() TaskOptions {
+	return t.options
+}
+
+// WithOptions задает параметры выполнения задачи
+func (t *BaseTask) WithOptions(options TaskOptions) *BaseTask {
+	t.options = options
+	return t
+}
+
 func (t *BaseTask) Run(ctx context.Context) error {
 	// Базовая реализация - ничего не делает
 	<-ctx.Done()
@@ -33,10 +46,10 @@ func (t *BaseTask) Stop(ctx context.Context) error {
 type TickerTask struct {
 	*BaseTask
 	interval time.Duration
-	handler  func(ctx context.Context) error
+	handler  HandlerFunc
 }
 
-func NewTickerTask(name string, interval time.Duration, handler func(ctx context.Context) error) *TickerTask {
+func NewTickerTask(name string, interval time.Duration, handler HandlerFunc) *TickerTask {
 	return &TickerTask{
 		BaseTask: NewBaseTask(name),
 		interval: interval,
@@ -44,35 +57,77 @@ func NewTickerTask(name string, interval time.Duration, handler func(ctx context
 	}
 }
 
+// WithOptions задает параметры выполнения задачи
+func (t *TickerTask) WithOptions(options TaskOptions) *TickerTask {
+	t.options = options
+	return t
+}
+
 func (t *TickerTask) Run(ctx context.Context) error {
 	ticker := time.NewTicker(t.interval)
 	defer ticker.Stop()
 
+	trigger := triggerChan(ctx)
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			if err := t.handler(ctx); err != nil {
-				return executionError("ticker task '%s' failed: %v", t.Name(), err)
-			}
+		case <-trigger:
+		}
+
+		err := execute(ctx, t.handler)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			// Задача остановлена во время выполнения, ошибка handler'а - следствие остановки
+			return ctx.Err()
 		}
+		if !t.options.ContinueOnError {
+			return executionError("ticker task '%s' failed: %v", t.Name(), err)
+		}
+
+		// Счетчик ведет воркер: пропущенные из-за паузы выполнения его не сбрасывают
+		failures := consecutiveFailures(ctx)
+		if limit := t.options.MaxConsecutiveFailures; limit > 0 && failures >= limit {
+			return executionError("ticker task '%s' failed %d times in a row: %v", t.Name(), failures, err)
+		}
+
+		agent.Logger.Warn().
+			Err(err).
+			Str("task", t.Name()).
+			Int("consecutive_failures", failures).
+			Msg("Ticker task execution failed, continuing")
 	}
 }
 
+// schedulable отмечает TickerTask как задачу с поддержкой ручного запуска и паузы
+func (t *TickerTask) schedulable() {}
+
+func (t *TickerTask) definition() any {
+	return t.interval
+}
+
 // OnceTask - задача, которая выполняется один раз
 type OnceTask struct {
 	*BaseTask
-	handler func(ctx context.Context) error
+	handler HandlerFunc
 }
 
-func NewOnceTask(name string, handler func(ctx context.Context) error) *OnceTask {
+func NewOnceTask(name string, handler HandlerFunc) *OnceTask {
 	return &OnceTask{
 		BaseTask: NewBaseTask(name),
 		handler:  handler,
 	}
 }
 
+// WithOptions задает параметры выполнения задачи
+func (t *OnceTask) WithOptions(options TaskOptions) *OnceTask {
+	t.options = options
+	return t
+}
+
 func (t *OnceTask) Run(ctx context.Context) error {
-	return t.handler(ctx)
+	return execute(ctx, t.handler)
 }
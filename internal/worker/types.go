@@ -9,7 +9,10 @@ type TaskStatus string
 
 const (
 	TaskStatusPending   TaskStatus = "pending"
+	TaskStatusQueued    TaskStatus = "queued"
 	TaskStatusRunning   TaskStatus = "running"
+	TaskStatusPaused    TaskStatus = "paused"
+	TaskStatusTriggered TaskStatus = "triggered"
 	TaskStatusStopping  TaskStatus = "stopping"
 	TaskStatusStopped   TaskStatus = "stopped"
 	TaskStatusFailed    TaskStatus = "failed"
@@ -33,17 +36,77 @@ type Task interface {
 	Stop(ctx context.Context) error
 }
 
+// TaskWithOptions задача, которая задает собственные параметры выполнения
+type TaskWithOptions interface {
+	Task
+	Options() TaskOptions
+}
+
+// TaskWrapper задача-обертка над другой задачей
+type TaskWrapper interface {
+	Task
+	Unwrap() Task
+}
+
+// HandlerFunc обработчик одного выполнения задачи
+type HandlerFunc func(ctx context.Context) error
+
+// TaskOptions параметры выполнения отдельной задачи
+type TaskOptions struct {
+	// ExecutionTimeout максимальное время одного вызова handler'а (0 = Config.TaskTimeout)
+	ExecutionTimeout time.Duration
+	// LifetimeTimeout максимальное время жизни задачи целиком (0 = без лимита)
+	LifetimeTimeout time.Duration
+	// Priority приоритет получения слота выполнения, большее значение получает слот раньше
+	Priority int
+	// Retry политика повторных попыток внутри одного выполнения (nil = без повторов)
+	Retry *RetryPolicy
+	// ContinueOnError продолжать работу TickerTask после ошибки выполнения
+	ContinueOnError bool
+	// MaxConsecutiveFailures количество ошибок подряд, после которого задача завершается
+	// с ошибкой при ContinueOnError (0 = без лимита). Считается по TaskInfo.ConsecutiveFailures,
+	// поэтому действует только для задач, запущенных воркером
+	MaxConsecutiveFailures int
+	// Essential задача продолжает работать, когда runner находится в degraded режиме
+	Essential bool
+}
+
 type TaskInfo struct {
 	Name      string
 	Status    TaskStatus
 	StartedAt *time.Time
 	StoppedAt *time.Time
 	Error     error
+
+	// LastQueueTime время ожидания слота выполнения последним запуском
+	LastQueueTime time.Duration
+	// TotalQueueTime суммарное время ожидания слотов выполнения
+	TotalQueueTime time.Duration
+
+	// LastSuccessAt время завершения последнего успешного выполнения
+	LastSuccessAt *time.Time
+
+	// ConsecutiveFailures количество неудачных выполнений подряд
+	ConsecutiveFailures int
+	// TotalFailures общее количество неудачных выполнений
+	TotalFailures int
+
+	// CircuitBreaker состояние circuit breaker'а, если задача обернута в CircuitBreakerTask
+	CircuitBreaker *CircuitBreakerStats
 }
 
 type taskWrapper struct {
-	task   Task
-	info   *TaskInfo
-	cancel context.CancelFunc
-	done   chan struct{}
+	task    Task
+	options TaskOptions
+	info    *TaskInfo
+	history *executionHistory
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	// trigger канал ручного запуска задачи
+	trigger chan struct{}
+	// triggered ручной запуск ожидает выполнения
+	triggered bool
+	// paused плановые выполнения задачи приостановлены
+	paused bool
 }
@@ -0,0 +1,286 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	agent "go-ex-vm-agent"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultWatchDebounce задержка по умолчанию, в течение которой события файловой системы собираются в одно
+const defaultWatchDebounce = 500 * time.Millisecond
+
+// FileWatchTask - задача, которая следит за файлами через fsnotify и вызывает обработчик,
+// когда содержимое одного из них действительно изменилось
+type FileWatchTask struct {
+	*BaseTask
+	paths    []string
+	debounce time.Duration
+	handler  HandlerFunc
+}
+
+// NewFileWatchTask создает задачу слежения за файлом path. События собираются в течение debounce
+// (0 = 500ms), после чего handler вызывается, только если изменился SHA-256 содержимого файла
+func NewFileWatchTask(name, path string, debounce time.Duration, handler HandlerFunc) *FileWatchTask {
+	return NewFilesWatchTask(name, []string{path}, debounce, handler)
+}
+
+// NewFilesWatchTask создает задачу слежения за несколькими файлами и каталогами, handler вызывается один раз
+// на изменение любого из них. Для каталога изменением считается появление, удаление или изменение его файлов
+func NewFilesWatchTask(name string, paths []string, debounce time.Duration, handler HandlerFunc) *FileWatchTask {
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+	return &FileWatchTask{
+		BaseTask: NewBaseTask(name),
+		paths:    paths,
+		debounce: debounce,
+		handler:  handler,
+	}
+}
+
+// WithOptions задает параметры выполнения задачи
+func (t *FileWatchTask) WithOptions(options TaskOptions) *FileWatchTask {
+	t.options = options
+	return t
+}
+
+// Run следит за файлами и за их каталогами. Наблюдение за каталогом нужно для записи через
+// временный файл и rename (и замены symlink'ов): в этом случае inode файла меняется и
+// наблюдение за самим файлом теряется. Ошибка обработчика не останавливает задачу
+func (t *FileWatchTask) Run(ctx context.Context) error {
+	if len(t.paths) == 0 {
+		return executionError("watch task '%s': no paths to watch", t.Name())
+	}
+	paths := make([]string, 0, len(t.paths))
+	for _, p := range t.paths {
+		path, err := filepath.Abs(p)
+		if err != nil || p == "" {
+			return executionError("watch task '%s': invalid path '%s': %v", t.Name(), p, err)
+		}
+		paths = append(paths, path)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return executionError("watch task '%s': failed to create watcher: %v", t.Name(), err)
+	}
+	defer watcher.Close()
+
+	for _, path := range paths {
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return executionError("watch task '%s': failed to watch dir '%s': %v", t.Name(), filepath.Dir(path), err)
+		}
+		// Файл может отсутствовать при запуске, его появление заметит наблюдение за каталогом
+		_ = watcher.Add(path)
+	}
+
+	targets := resolveTargets(paths)
+	hashes, err := pathHashes(paths)
+	if err != nil {
+		agent.Logger.Warn().
+			Err(err).
+			Str("task", t.Name()).
+			Msg("Failed to read watched file")
+	}
+
+	debounce := time.NewTimer(t.debounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	trigger := triggerChan(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return executionError("watch task '%s': watcher closed", t.Name())
+			}
+			if !isWatchedEvent(event, paths, targets) {
+				continue
+			}
+			agent.Logger.Debug().
+				Str("task", t.Name()).
+				Str("event", event.String()).
+				Msg("File system event")
+			debounce.Reset(t.debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return executionError("watch task '%s': watcher closed", t.Name())
+			}
+			agent.Logger.Warn().
+				Err(err).
+				Str("task", t.Name()).
+				Msg("File watcher error")
+
+		case <-debounce.C:
+			// После rename наблюдение за старым inode снято, добавляем файлы заново
+			for _, path := range paths {
+				_ = watcher.Add(path)
+			}
+			targets = resolveTargets(paths)
+
+			current, err := pathHashes(paths)
+			if err != nil {
+				// Файл удален или еще не записан - ждем следующего события
+				agent.Logger.Warn().
+					Err(err).
+					Str("task", t.Name()).
+					Msg("Failed to read watched file")
+				continue
+			}
+			changed := changedPaths(hashes, current)
+			if len(changed) == 0 {
+				continue
+			}
+
+			agent.Logger.Info().
+				Str("task", t.Name()).
+				Strs("paths", changed).
+				Msg("Watched file changed")
+			hashes = current
+			t.handle(ctx)
+
+		case <-trigger:
+			t.handle(ctx)
+		}
+	}
+}
+
+// schedulable отмечает FileWatchTask как задачу с поддержкой ручного запуска и паузы
+func (t *FileWatchTask) schedulable() {}
+
+func (t *FileWatchTask) definition() any {
+	return [2]any{t.paths, t.debounce}
+}
+
+func (t *FileWatchTask) handle(ctx context.Context) {
+	if err := execute(ctx, t.handler); err != nil && ctx.Err() == nil {
+		agent.Logger.Warn().
+			Err(err).
+			Str("task", t.Name()).
+			Msg("File watch handler failed")
+	}
+}
+
+// isWatchedEvent отбрасывает события других файлов каталогов. Кроме самих путей учитываются
+// цели symlink'ов, файлы отслеживаемых каталогов и служебные записи вида "..data", через которые
+// Kubernetes атомарно подменяет ConfigMap
+func isWatchedEvent(event fsnotify.Event, paths []string, targets map[string]string) bool {
+	name := filepath.Clean(event.Name)
+	if strings.HasPrefix(filepath.Base(name), "..") {
+		return true
+	}
+	for _, path := range paths {
+		if name == path || name == targets[path] || filepath.Dir(name) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTargets возвращает пути, на которые указывают paths после разрешения symlink'ов
+func resolveTargets(paths []string) map[string]string {
+	targets := make(map[string]string, len(paths))
+	for _, path := range paths {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			target = path
+		}
+		targets[path] = target
+	}
+	return targets
+}
+
+// pathHashes возвращает hex SHA-256 содержимого каждого пути. Ошибка чтения одного пути
+// не мешает посчитать остальные, первая из ошибок возвращается вместе с результатом
+func pathHashes(paths []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(paths))
+	var firstErr error
+	for _, path := range paths {
+		hash, err := pathHash(path)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", path, err)
+		}
+		hashes[path] = hash
+	}
+	return hashes, firstErr
+}
+
+// changedPaths возвращает пути, SHA-256 которых отличается
+func changedPaths(previous, current map[string]string) []string {
+	var changed []string
+	for path, hash := range current {
+		if previous[path] != hash {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// pathHash возвращает hex SHA-256 содержимого файла. Для каталога хеш считается по именам
+// и содержимому его файлов, вложенные каталоги не учитываются
+func pathHash(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return fileHash(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		hash, err := fileHash(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %s\n", entry.Name(), hash)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileHash возвращает hex SHA-256 содержимого файла
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", errors.New("path is a directory")
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilesWatchTask(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "config.yaml")
+	include := filepath.Join(t.TempDir(), "include.yaml")
+	dropIns := filepath.Join(dir, "conf.d")
+	for _, path := range []string{main, include} {
+		if err := os.WriteFile(path, []byte("a: 1\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(dropIns, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := make(chan struct{}, 10)
+	task := NewFilesWatchTask("watch", []string{main, include, dropIns}, 20*time.Millisecond, func(ctx context.Context) error {
+		changes <- struct{}{}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- task.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// Наблюдение устанавливается в начале Run
+	time.Sleep(50 * time.Millisecond)
+
+	steps := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "include changed", write: func() error { return os.WriteFile(include, []byte("a: 2\n"), 0o600) }},
+		{name: "drop-in added", write: func() error {
+			return os.WriteFile(filepath.Join(dropIns, "10-extra.yaml"), []byte("b: 1\n"), 0o600)
+		}},
+		{name: "main file replaced by rename", write: func() error {
+			tmp := filepath.Join(dir, "config.yaml.tmp")
+			if err := os.WriteFile(tmp, []byte("a: 3\n"), 0o600); err != nil {
+				return err
+			}
+			return os.Rename(tmp, main)
+		}},
+	}
+	for _, step := range steps {
+		if err := step.write(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-changes:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: handler was not called", step.name)
+		}
+	}
+
+	// Запись того же содержимого не вызывает handler
+	if err := os.WriteFile(include, []byte("a: 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+		t.Fatal("handler called for unchanged content")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestFilesWatchTaskRejectsEmptyPath(t *testing.T) {
+	task := NewFileWatchTask("watch", "", 0, func(ctx context.Context) error { return nil })
+	if err := task.Run(context.Background()); err == nil {
+		t.Fatal("Run() with an empty path error = nil")
+	}
+}
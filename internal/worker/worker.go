@@ -2,30 +2,51 @@ package worker
 
 import (
 	"context"
+	"errors"
 	agent "go-ex-vm-agent"
+	"go-ex-vm-agent/internal/events"
 	"sync"
 	"time"
 )
 
 type Worker struct {
-	tasks map[string]*taskWrapper
+	tasks   map[string]*taskWrapper
+	limiter *limiter
+	events  *events.Bus
+
+	// savedHistory история выполнений незарегистрированных задач: загруженная из HistoryFile
+	// и удаленных задач. Сохраняется в HistoryFile вместе с историей зарегистрированных задач
+	savedHistory map[string][]ExecutionRecord
+
+	// manageMu выполняет операции управления задачами (AddTask, RemoveTask, ReplaceTask) по очереди
+	manageMu sync.Mutex
 
 	// TODO: maybe atomic from pointers?
 	mu     sync.RWMutex
 	config Config
 	status WorkerStatus
 
+	// ctx контекст, с которым был запущен воркер, используется для задач, добавленных на лету
+	ctx context.Context
+
 	stopCh chan struct{}
 	doneCh chan struct{}
 }
 
 func New(config Config) (*Worker, error) {
 	if err := config.Validate(); err != nil {
-		return nil, initError(err.Error())
+		return nil, initError("%s", err.Error())
+	}
+
+	history, err := loadHistory(config.HistoryFile)
+	if err != nil {
+		return nil, initError("failed to load task history: %v", err)
 	}
 
 	return &Worker{
-		tasks: make(map[string]*taskWrapper),
+		tasks:        make(map[string]*taskWrapper),
+		limiter:      newLimiter(config.MaxTasks),
+		savedHistory: history,
 
 		config: config,
 		status: WorkerStatusIdle,
@@ -35,35 +56,23 @@ func New(config Config) (*Worker, error) {
 }
 
 func (w *Worker) RegisterTask(task Task) error {
-	if task == nil {
-		return registrationError("task cannot be nil")
+	if err := validateTask(task); err != nil {
+		return err
 	}
 	name := task.Name()
-	if name == "" {
-		return registrationError("task name cannot be empty")
-	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	if _, exists := w.tasks[name]; exists {
 		return registrationError("task '%s' already registered", name)
 	}
-	if len(w.tasks) >= w.config.MaxTasks {
-		return registrationError("cannot register task '%s': maximum tasks limit (%d) reached", name, w.config.MaxTasks)
-	}
 	// TODO: maybe error status only
 	if w.status != WorkerStatusIdle {
 		return registrationError("cannot register task '%s': worker is not idle", name)
 	}
 
-	w.tasks[name] = &taskWrapper{
-		task: task,
-		info: &TaskInfo{
-			Name:   name,
-			Status: TaskStatusPending,
-		},
-		done: make(chan struct{}),
-	}
+	w.tasks[name] = w.newWrapper(task)
 
 	agent.Logger.Info().
 		Str("task", name).
@@ -71,6 +80,123 @@ func (w *Worker) RegisterTask(task Task) error {
 	return nil
 }
 
+// AddTask добавляет задачу в воркер. Если воркер уже запущен, задача сразу стартует
+func (w *Worker) AddTask(task Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	name := task.Name()
+
+	w.manageMu.Lock()
+	defer w.manageMu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.tasks[name]; exists {
+		return registrationError("task '%s' already registered", name)
+	}
+
+	switch w.status {
+	case WorkerStatusIdle:
+		w.tasks[name] = w.newWrapper(task)
+	case WorkerStatusRunning:
+		wrapper := w.newWrapper(task)
+		if err := w.startTask(w.ctx, wrapper); err != nil {
+			return registrationError("failed to start task '%s': %v", name, err)
+		}
+		w.tasks[name] = wrapper
+	default:
+		return registrationError("cannot add task '%s': worker status is %s", name, w.status)
+	}
+
+	agent.Logger.Info().
+		Str("task", name).
+		Str("worker_status", string(w.status)).
+		Msg("Task added")
+	return nil
+}
+
+// RemoveTask останавливает задачу (с учетом TaskStopTimeout) и удаляет ее из воркера
+func (w *Worker) RemoveTask(ctx context.Context, name string) error {
+	w.manageMu.Lock()
+	defer w.manageMu.Unlock()
+
+	wrapper, err := w.detachTask(name)
+	if err != nil {
+		return err
+	}
+
+	w.stopManagedTask(ctx, wrapper)
+
+	// История удаленной задачи сохраняется и достанется задаче с тем же именем, если ее добавят снова
+	w.mu.Lock()
+	w.savedHistory[name] = wrapper.history.list()
+	w.mu.Unlock()
+
+	agent.Logger.Info().
+		Str("task", name).
+		Msg("Task removed")
+	return nil
+}
+
+// ReplaceTask останавливает задачу с тем же именем и запускает вместо нее новую, новая задача
+// получает историю и счетчики старой. На время остановки старая задача остается в воркере,
+// а операции управления задачами выполняются по очереди, поэтому имя не может занять другая задача.
+// Если воркер начал останавливаться, пока останавливалась старая задача, новая не запускается
+func (w *Worker) ReplaceTask(ctx context.Context, task Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	name := task.Name()
+
+	w.manageMu.Lock()
+	defer w.manageMu.Unlock()
+
+	w.mu.Lock()
+	old, exists := w.tasks[name]
+	status := w.status
+	if exists && status == WorkerStatusIdle {
+		// Задача еще не запущена, останавливать нечего
+		w.tasks[name] = w.newReplacement(task, old)
+	}
+	w.mu.Unlock()
+
+	switch {
+	case !exists:
+		return manageError("task '%s' not found", name)
+	case status == WorkerStatusRunning:
+		if err := w.replaceRunningTask(ctx, old, task); err != nil {
+			return err
+		}
+	case status != WorkerStatusIdle:
+		return manageError("cannot manage task '%s': worker status is %s", name, status)
+	}
+
+	agent.Logger.Info().
+		Str("task", name).
+		Msg("Task replaced")
+	return nil
+}
+
+// replaceRunningTask останавливает задачу old работающего воркера и запускает вместо нее task
+func (w *Worker) replaceRunningTask(ctx context.Context, old *taskWrapper, task Task) error {
+	w.stopManagedTask(ctx, old)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.status != WorkerStatusRunning {
+		return manageError("failed to replace task '%s': worker status is %s", task.Name(), w.status)
+	}
+	wrapper := w.newReplacement(task, old)
+	if err := w.startTask(w.ctx, wrapper); err != nil {
+		return manageError("failed to replace task '%s': %v", task.Name(), err)
+	}
+	w.tasks[task.Name()] = wrapper
+	return nil
+}
+
 func (w *Worker) Start(ctx context.Context) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -85,6 +211,7 @@ func (w *Worker) Start(ctx context.Context) error {
 	}
 
 	w.status = WorkerStatusStarting
+	w.ctx = ctx
 	agent.Logger.Info().
 		Int("task_count", len(w.tasks)).
 		Msg("Starting worker")
@@ -148,25 +275,51 @@ func (w *Worker) Stop(ctx context.Context) error {
 	w.status = WorkerStatusStopped
 	w.mu.Unlock()
 
+	w.saveHistory()
+
 	close(w.doneCh)
 	agent.Logger.Info().Msg("Worker stopped")
 
 	return nil
 }
 
+// SetEventBus подключает шину, в которую воркер публикует события задач. Вызывается до Start
+func (w *Worker) SetEventBus(bus *events.Bus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.events = bus
+}
+
 func (w *Worker) GetStatus() WorkerStatus {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 	return w.status
 }
 
+// GetTask возвращает зарегистрированную задачу по имени
+func (w *Worker) GetTask(name string) (Task, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	wrapper, ok := w.tasks[name]
+	if !ok {
+		return nil, false
+	}
+	return wrapper.task, true
+}
+
 func (w *Worker) GetTasksInfo() map[string]TaskInfo {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
 	info := make(map[string]TaskInfo)
 	for name, wrapper := range w.tasks {
-		info[name] = *wrapper.info
+		taskInfo := *wrapper.info
+		if breaker, ok := wrapper.task.(*CircuitBreakerTask); ok {
+			stats := breaker.CircuitBreakerStats()
+			taskInfo.CircuitBreaker = &stats
+		}
+		info[name] = taskInfo
 	}
 	return info
 }
@@ -176,11 +329,16 @@ func (w *Worker) Wait() {
 }
 
 func (w *Worker) startTask(ctx context.Context, wrapper *taskWrapper) error {
-	taskCtx, cancel := context.WithCancel(ctx)
+	taskCtx := withFailures(withExecutor(ctx, w.newExecutor(wrapper)), func() int {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		return wrapper.info.ConsecutiveFailures
+	})
+	taskCtx, cancel := context.WithCancel(withTrigger(taskCtx, wrapper.trigger))
 	wrapper.cancel = cancel
 
-	if w.config.TaskTimeout > 0 {
-		taskCtx, cancel = context.WithTimeout(taskCtx, w.config.TaskTimeout)
+	if timeout := wrapper.options.LifetimeTimeout; timeout > 0 {
+		taskCtx, cancel = context.WithTimeout(taskCtx, timeout)
 		originalCancel := wrapper.cancel
 		wrapper.cancel = func() {
 			cancel()
@@ -191,26 +349,39 @@ func (w *Worker) startTask(ctx context.Context, wrapper *taskWrapper) error {
 	now := time.Now()
 	wrapper.info.Status = TaskStatusRunning
 	wrapper.info.StartedAt = &now
+	w.publish(events.EventTaskStarted, wrapper, nil, nil)
 
 	go func() {
 		defer close(wrapper.done)
-		defer func() {
+		defer w.updateInfo(wrapper, func(info *TaskInfo) {
 			now := time.Now()
-			wrapper.info.StoppedAt = &now
-		}()
+			info.StoppedAt = &now
+		})
 
 		agent.Logger.Debug().
 			Str("task", wrapper.task.Name()).
 			Msg("Starting task")
 
-		if err := wrapper.task.Run(taskCtx); err != nil {
-			wrapper.info.Status = TaskStatusFailed
-			wrapper.info.Error = err
+		err := wrapper.task.Run(taskCtx)
+		if err != nil && errors.Is(err, context.Canceled) && errors.Is(taskCtx.Err(), context.Canceled) {
+			// Задача остановлена через stopTask - это не ошибка, статус выставит stopTask
+			return
+		}
+		if err != nil && wrapper.options.LifetimeTimeout > 0 && errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+			err = timeoutError("task '%s' lifetime timeout %v exceeded", wrapper.task.Name(), wrapper.options.LifetimeTimeout)
+			w.publish(events.EventTaskTimedOut, wrapper, err, map[string]string{"scope": "lifetime"})
+		}
+		if err != nil {
+			w.updateInfo(wrapper, func(info *TaskInfo) {
+				info.Status = TaskStatusFailed
+				info.Error = err
+			})
 
 			agent.Logger.Error().
 				Err(err).
 				Str("task", wrapper.task.Name()).
 				Msg("Task failed")
+			w.publish(events.EventTaskFailed, wrapper, err, nil)
 
 			if w.config.StopOnError {
 				go func() {
@@ -224,16 +395,137 @@ func (w *Worker) startTask(ctx context.Context, wrapper *taskWrapper) error {
 			return
 		}
 
-		wrapper.info.Status = TaskStatusCompleted
+		w.updateInfo(wrapper, func(info *TaskInfo) {
+			info.Status = TaskStatusCompleted
+		})
 		agent.Logger.Debug().
 			Str("task", wrapper.task.Name()).
 			Msg("Task completed")
+		w.publish(events.EventTaskCompleted, wrapper, nil, nil)
 	}()
 	return nil
 }
 
+// newWrapper создает обертку для новой задачи. Задача получает сохраненную историю задачи
+// с тем же именем: из HistoryFile или удаленной ранее. Вызывается под блокировкой воркера
+func (w *Worker) newWrapper(task Task) *taskWrapper {
+	history := newExecutionHistory(w.config.HistorySize, w.savedHistory[task.Name()])
+	delete(w.savedHistory, task.Name())
+
+	return &taskWrapper{
+		task:    task,
+		options: w.resolveOptions(task),
+		history: history,
+		info: &TaskInfo{
+			Name:   task.Name(),
+			Status: TaskStatusPending,
+		},
+		done:    make(chan struct{}),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// newReplacement создает обертку для задачи, которая заменяет задачу old.
+// Вызывается под блокировкой воркера
+func (w *Worker) newReplacement(task Task, old *taskWrapper) *taskWrapper {
+	wrapper := w.newWrapper(task)
+	wrapper.inherit(old)
+	return wrapper
+}
+
+// inherit переносит в обертку новой задачи историю, счетчики и состояние circuit breaker'а
+// заменяемой задачи
+func (wrapper *taskWrapper) inherit(old *taskWrapper) {
+	wrapper.history = old.history
+	wrapper.info.LastSuccessAt = old.info.LastSuccessAt
+	wrapper.info.ConsecutiveFailures = old.info.ConsecutiveFailures
+	wrapper.info.TotalFailures = old.info.TotalFailures
+	wrapper.info.TotalQueueTime = old.info.TotalQueueTime
+
+	breaker, ok := wrapper.task.(*CircuitBreakerTask)
+	if !ok {
+		return
+	}
+	if oldBreaker, ok := old.task.(*CircuitBreakerTask); ok {
+		breaker.inheritState(oldBreaker)
+	}
+}
+
+// detachTask удаляет задачу из списка задач воркера и возвращает ее обертку
+func (w *Worker) detachTask(name string) (*taskWrapper, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wrapper, exists := w.tasks[name]
+	if !exists {
+		return nil, manageError("task '%s' not found", name)
+	}
+	if w.status != WorkerStatusIdle && w.status != WorkerStatusRunning {
+		return nil, manageError("cannot manage task '%s': worker status is %s", name, w.status)
+	}
+
+	delete(w.tasks, name)
+	return wrapper, nil
+}
+
+// stopManagedTask останавливает удаляемую или заменяемую задачу с учетом TaskStopTimeout
+func (w *Worker) stopManagedTask(ctx context.Context, wrapper *taskWrapper) {
+	if wrapper.info.StartedAt == nil {
+		return
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, w.config.TaskStopTimeout)
+	defer cancel()
+	w.stopTask(stopCtx, wrapper)
+}
+
+// resolveOptions возвращает параметры выполнения задачи с учетом значений по умолчанию из конфигурации
+func (w *Worker) resolveOptions(task Task) TaskOptions {
+	var options TaskOptions
+	if t, ok := task.(TaskWithOptions); ok {
+		options = t.Options()
+	}
+	if options.ExecutionTimeout == 0 {
+		options.ExecutionTimeout = w.config.TaskTimeout
+	}
+	return options
+}
+
+// publish отправляет событие задачи в шину событий, если она подключена
+func (w *Worker) publish(eventType events.EventType, wrapper *taskWrapper, err error, attributes map[string]string) {
+	event := events.Event{
+		Type:       eventType,
+		Task:       wrapper.task.Name(),
+		Attributes: attributes,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	w.events.Publish(event)
+}
+
+// updateInfo изменяет информацию о задаче под блокировкой воркера
+func (w *Worker) updateInfo(wrapper *taskWrapper, update func(info *TaskInfo)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	update(wrapper.info)
+}
+
+// validateTask проверяет, что задачу можно зарегистрировать
+func validateTask(task Task) error {
+	if task == nil {
+		return registrationError("task cannot be nil")
+	}
+	if task.Name() == "" {
+		return registrationError("task name cannot be empty")
+	}
+	return nil
+}
+
 func (w *Worker) stopTask(ctx context.Context, wrapper *taskWrapper) {
-	wrapper.info.Status = TaskStatusStopping
+	w.updateInfo(wrapper, func(info *TaskInfo) {
+		info.Status = TaskStatusStopping
+	})
 
 	agent.Logger.Debug().
 		Str("task", wrapper.task.Name()).
@@ -252,18 +544,25 @@ func (w *Worker) stopTask(ctx context.Context, wrapper *taskWrapper) {
 
 	select {
 	case <-wrapper.done:
-		if wrapper.info.Status != TaskStatusFailed {
-			wrapper.info.Status = TaskStatusStopped
-		}
+		w.updateInfo(wrapper, func(info *TaskInfo) {
+			if info.Status != TaskStatusFailed {
+				info.Status = TaskStatusStopped
+			}
+		})
 		agent.Logger.Debug().
 			Str("task", wrapper.task.Name()).
 			Msg("Task stopped")
+		w.publish(events.EventTaskStopped, wrapper, nil, nil)
 	case <-ctx.Done():
-		wrapper.info.Status = TaskStatusFailed
-		wrapper.info.Error = timeoutError("task '%s' stop timeout", wrapper.task.Name())
+		err := timeoutError("task '%s' stop timeout", wrapper.task.Name())
+		w.updateInfo(wrapper, func(info *TaskInfo) {
+			info.Status = TaskStatusFailed
+			info.Error = err
+		})
 		agent.Logger.Warn().
 			Str("task", wrapper.task.Name()).
 			Msg("Task stop timeout")
+		w.publish(events.EventTaskTimedOut, wrapper, err, map[string]string{"scope": "stop"})
 	}
 }
 
@@ -279,6 +578,7 @@ func (w *Worker) monitor(ctx context.Context) {
 			return
 		case <-ticker.C:
 			w.logTasksStatus()
+			w.saveHistory()
 		}
 	}
 }
@@ -288,13 +588,16 @@ func (w *Worker) logTasksStatus() {
 	defer w.mu.RUnlock()
 
 	running := 0
+	paused := 0
 	failed := 0
 	completed := 0
 
 	for _, wrapper := range w.tasks {
 		switch wrapper.info.Status {
-		case TaskStatusRunning:
+		case TaskStatusRunning, TaskStatusQueued, TaskStatusTriggered:
 			running++
+		case TaskStatusPaused:
+			paused++
 		case TaskStatusFailed:
 			failed++
 		case TaskStatusCompleted:
@@ -305,6 +608,7 @@ func (w *Worker) logTasksStatus() {
 	agent.Logger.Debug().
 		Int("total", len(w.tasks)).
 		Int("running", running).
+		Int("paused", paused).
 		Int("failed", failed).
 		Int("completed", completed).
 		Msg("Tasks status")
@@ -0,0 +1,241 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-ex-vm-agent/internal/events"
+)
+
+func TestWorkerStopDuringExecution(t *testing.T) {
+	w := newTestWorker(t)
+	bus := events.NewBus()
+	sub := bus.Subscribe(64)
+	defer sub.Close()
+	w.SetEventBus(bus)
+
+	started := make(chan struct{})
+	task := NewTickerTask("tick", time.Millisecond, func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := w.RegisterTask(task); err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	<-started
+
+	if err := w.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	info := w.GetTasksInfo()["tick"]
+	if info.Status != TaskStatusStopped || info.Error != nil {
+		t.Fatalf("info = %+v, want stopped without error", info)
+	}
+	for _, event := range drainEvents(sub) {
+		if event.Type == events.EventTaskFailed {
+			t.Fatalf("event %+v published on stop", event)
+		}
+	}
+}
+
+// newTestWorker создает воркер с небольшими таймаутами для тестов
+func newTestWorker(t *testing.T) *Worker {
+	t.Helper()
+
+	w, err := New(Config{MaxTasks: 4, TaskTimeout: time.Second, TaskStopTimeout: time.Second, HistorySize: 10})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return w
+}
+
+// drainEvents возвращает события, уже доставленные подписке
+func drainEvents(sub *events.Subscription) []events.Event {
+	var received []events.Event
+	for {
+		select {
+		case event := <-sub.Events():
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestWorkerPublishesExecutionFailures(t *testing.T) {
+	w := newTestWorker(t)
+	bus := events.NewBus()
+	sub := bus.Subscribe(64, events.EventTaskFailed)
+	defer sub.Close()
+	w.SetEventBus(bus)
+
+	task := NewTickerTask("tick", time.Millisecond, func(ctx context.Context) error {
+		return errors.New("unavailable")
+	}).WithOptions(TaskOptions{ContinueOnError: true})
+	if err := w.RegisterTask(task); err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop(context.Background())
+
+	select {
+	case event := <-sub.Events():
+		if event.Task != "tick" || event.Attributes["scope"] != "execution" || event.Error != "unavailable" {
+			t.Fatalf("event = %+v, want execution failure of 'tick'", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no task.failed event for a failed execution")
+	}
+	if status := w.GetTasksInfo()["tick"].Status; status == TaskStatusFailed {
+		t.Fatalf("task status = %s, want the task to keep running", status)
+	}
+}
+
+func TestWorkerAddTask(t *testing.T) {
+	w := newTestWorker(t)
+	if err := w.RegisterTask(NewBaseTask("base")); err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop(context.Background())
+
+	calls := make(chan struct{}, 1)
+	task := NewOnceTask("added", func(ctx context.Context) error {
+		calls <- struct{}{}
+		return nil
+	})
+	if err := w.AddTask(task); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("task added to a running worker was not started")
+	}
+	waitForTaskStatus(t, w, "added", TaskStatusCompleted)
+
+	if err := w.AddTask(NewBaseTask("added")); err == nil {
+		t.Fatal("AddTask() with a registered name error = nil")
+	}
+}
+
+func TestWorkerRemoveTask(t *testing.T) {
+	w := newTestWorker(t)
+	stopped := make(chan struct{})
+	task := NewTickerTask("tick", time.Millisecond, func(ctx context.Context) error {
+		return nil
+	})
+	for _, task := range []Task{NewBaseTask("base"), &stopNotifier{Task: task, stopped: stopped}} {
+		if err := w.RegisterTask(task); err != nil {
+			t.Fatalf("RegisterTask() error = %v", err)
+		}
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop(context.Background())
+
+	if err := w.RemoveTask(context.Background(), "tick"); err != nil {
+		t.Fatalf("RemoveTask() error = %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("removed task was not stopped")
+	}
+	if _, ok := w.GetTask("tick"); ok {
+		t.Fatal("removed task is still registered")
+	}
+	if _, ok := w.GetTasksInfo()["base"]; !ok {
+		t.Fatal("other tasks were removed")
+	}
+	if err := w.RemoveTask(context.Background(), "tick"); err == nil {
+		t.Fatal("RemoveTask() of a removed task error = nil")
+	}
+}
+
+func TestWorkerReplaceTask(t *testing.T) {
+	w := newTestWorker(t)
+	old := NewTickerTask("tick", time.Millisecond, func(ctx context.Context) error {
+		return errors.New("old handler")
+	}).WithOptions(TaskOptions{ContinueOnError: true})
+	if err := w.RegisterTask(old); err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer w.Stop(context.Background())
+	waitForExecutions(t, w, "tick", 2)
+
+	calls := make(chan struct{}, 1)
+	replacement := NewTickerTask("tick", time.Millisecond, func(ctx context.Context) error {
+		select {
+		case calls <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	if err := w.ReplaceTask(context.Background(), replacement); err != nil {
+		t.Fatalf("ReplaceTask() error = %v", err)
+	}
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("replacement task was not started")
+	}
+
+	if task, _ := w.GetTask("tick"); task != Task(replacement) {
+		t.Fatalf("GetTask() = %v, want the replacement task", task)
+	}
+	info := w.GetTasksInfo()["tick"]
+	if info.TotalFailures < 2 || info.Status == TaskStatusStopped {
+		t.Fatalf("info = %+v, want a running task with the failures of the old task", info)
+	}
+	history, err := w.GetTaskHistory("tick")
+	if err != nil || history[0].Error != "old handler" {
+		t.Fatalf("GetTaskHistory() = %+v, %v, want the history of the old task first", history, err)
+	}
+	if err := w.ReplaceTask(context.Background(), NewBaseTask("missing")); err == nil {
+		t.Fatal("ReplaceTask() of an unknown task error = nil")
+	}
+}
+
+// stopNotifier закрывает канал stopped после остановки задачи
+type stopNotifier struct {
+	Task
+	stopped chan struct{}
+}
+
+func (t *stopNotifier) Run(ctx context.Context) error {
+	defer close(t.stopped)
+	return t.Task.Run(ctx)
+}
+
+// waitForExecutions ждет, пока в истории задачи накопится не меньше count выполнений
+func waitForExecutions(t *testing.T, w *Worker, name string, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if history, _ := w.GetTaskHistory(name); len(history) >= count {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("task '%s' has fewer than %d executions", name, count)
+}